@@ -0,0 +1,75 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// Components are the collector specs of an Agent, named after the config map they end up in.
+var Components = []string{"agent", "cluster-receiver", "gateway"}
+
+// CollectorSpec returns the spec of the named component, or nil for an unknown component.
+func (r *Agent) CollectorSpec(component string) *CollectorSpec {
+	switch component {
+	case "agent":
+		return &r.Spec.Agent
+	case "cluster-receiver":
+		return &r.Spec.ClusterReceiver
+	case "gateway":
+		return &r.Spec.Gateway
+	}
+	return nil
+}
+
+// ComponentEnabled reports whether the named component is deployed. The agent and cluster receiver are deployed
+// unless disabled, the gateway only when enabled.
+func (r *Agent) ComponentEnabled(component string) bool {
+	spec := r.CollectorSpec(component)
+	if spec == nil {
+		return false
+	}
+	if spec.Enabled == nil {
+		return component != "gateway"
+	}
+	return *spec.Enabled
+}
+
+// ServiceEnabled reports whether the services exposing the named component are created. Services are created by
+// default for the gateway only.
+func (r *Agent) ServiceEnabled(component string) bool {
+	if !r.ComponentEnabled(component) {
+		return false
+	}
+	spec := r.CollectorSpec(component)
+	if spec.ServiceEnabled == nil {
+		return component == "gateway"
+	}
+	return *spec.ServiceEnabled
+}
+
+// AnnotationReconcile pauses the reconciliation of an Agent when set to ReconcilePaused. Suffixed with the name of a
+// component, like "otel.splunk.com/reconcile-gateway", it pauses the reconciliation of that component only.
+const AnnotationReconcile = "otel.splunk.com/reconcile"
+
+// ReconcilePaused is the value of the AnnotationReconcile annotations pausing the reconciliation.
+const ReconcilePaused = "paused"
+
+// Paused reports whether the reconciliation of the whole Agent is paused.
+func (r *Agent) Paused() bool {
+	return r.Annotations[AnnotationReconcile] == ReconcilePaused
+}
+
+// ComponentPaused reports whether the reconciliation of the named component is paused, on its own or along with the
+// whole Agent. The objects of a paused component are neither updated nor deleted.
+func (r *Agent) ComponentPaused(component string) bool {
+	return r.Paused() || r.Annotations[AnnotationReconcile+"-"+component] == ReconcilePaused
+}
@@ -0,0 +1,63 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceEnabledDefaults(t *testing.T) {
+	var a = Agent{}
+	assert.False(t, a.ServiceEnabled("agent"))
+	assert.False(t, a.ServiceEnabled("cluster-receiver"))
+	assert.False(t, a.ServiceEnabled("gateway"), "The gateway isn't deployed unless enabled")
+
+	enabled := true
+	a.Spec.Gateway.Enabled = &enabled
+	assert.True(t, a.ServiceEnabled("gateway"))
+}
+
+func TestServiceEnabledRequiresComponent(t *testing.T) {
+	enabled, disabled := true, false
+	var a = Agent{}
+	a.Spec.Agent.ServiceEnabled = &enabled
+	assert.True(t, a.ServiceEnabled("agent"))
+
+	a.Spec.Agent.Enabled = &disabled
+	assert.False(t, a.ServiceEnabled("agent"), "A disabled agent shouldn't be exposed")
+	assert.False(t, a.ServiceEnabled("unknown"))
+}
+
+func TestPaused(t *testing.T) {
+	var a = Agent{}
+	assert.False(t, a.Paused())
+	assert.False(t, a.ComponentPaused("agent"))
+
+	a.Annotations = map[string]string{"otel.splunk.com/reconcile-gateway": "paused"}
+	assert.False(t, a.Paused())
+	assert.True(t, a.ComponentPaused("gateway"))
+	assert.False(t, a.ComponentPaused("agent"))
+
+	a.Annotations = map[string]string{"otel.splunk.com/reconcile": "paused"}
+	assert.True(t, a.Paused())
+	for _, component := range Components {
+		assert.True(t, a.ComponentPaused(component), component)
+	}
+
+	a.Annotations = map[string]string{"otel.splunk.com/reconcile": "enabled"}
+	assert.False(t, a.Paused())
+}
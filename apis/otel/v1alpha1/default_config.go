@@ -0,0 +1,109 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/signalfx/splunk-otel-collector-operator/internal/autodetect"
+)
+
+// DefaultConfigAnnotation returns the annotation recording the hash of the operator default config the named
+// component was given. Configs whose hash doesn't match the annotation anymore are owned by the user.
+func DefaultConfigAnnotation(component string) string {
+	return fmt.Sprintf("otel.splunk.com/%s-default-config", component)
+}
+
+// UsesDefaultConfig reports whether the named component still runs the operator default config it was given,
+// as opposed to a config authored by the user.
+func (r *Agent) UsesDefaultConfig(component string) bool {
+	spec := r.CollectorSpec(component)
+	if spec == nil {
+		return false
+	}
+
+	hash, ok := r.Annotations[DefaultConfigAnnotation(component)]
+	return ok && hash == configHash(spec.Config)
+}
+
+// DefaultConfigOutdated reports whether the named component runs the default config of another operator version,
+// that is, whether the hash recorded in its annotation differs from the hash of the default config of this operator.
+func (r *Agent) DefaultConfigOutdated(component string) bool {
+	if !r.UsesDefaultConfig(component) {
+		return false
+	}
+	return r.Annotations[DefaultConfigAnnotation(component)] != configHash(defaultConfigFor(component))
+}
+
+// RefreshDefaultConfig replaces the config of the named component with the default config of this operator, when the
+// component still runs the default config of an older operator. It reports whether the config was changed.
+func (r *Agent) RefreshDefaultConfig(component string) bool {
+	if !r.DefaultConfigOutdated(component) {
+		return false
+	}
+
+	r.CollectorSpec(component).Config = defaultConfigFor(component)
+	r.trackDefaultConfig(component)
+	return true
+}
+
+// trackDefaultConfig records whether the named component runs the operator default config, so that it can be refreshed
+// when a newer operator ships a different default.
+func (r *Agent) trackDefaultConfig(component string) {
+	spec := r.CollectorSpec(component)
+	key := DefaultConfigAnnotation(component)
+	hash := configHash(spec.Config)
+
+	// the annotations map may be shared with a copy of this object, don't write to it in place
+	annotations := make(map[string]string, len(r.Annotations)+1)
+	for k, v := range r.Annotations {
+		annotations[k] = v
+	}
+
+	switch {
+	case spec.Config == defaultConfigFor(component):
+		annotations[key] = hash
+	case annotations[key] == hash:
+		// still the default config of the operator version that set it, keep tracking it
+		return
+	default:
+		delete(annotations, key)
+	}
+
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	r.Annotations = annotations
+}
+
+func defaultConfigFor(component string) string {
+	switch component {
+	case "agent":
+		return defaultAgentConfig
+	case "cluster-receiver":
+		if detectedDistro == autodetect.OpenShiftDistro {
+			return defaultClusterReceiverConfigOpenshift
+		}
+		return defaultClusterReceiverConfig
+	case "gateway":
+		return defaultGatewayConfig
+	}
+	return ""
+}
+
+func configHash(config string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(config)))
+}
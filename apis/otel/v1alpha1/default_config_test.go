@@ -0,0 +1,84 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultTracksDefaultConfigs(t *testing.T) {
+	var a = Agent{}
+	a.Default()
+	for _, component := range Components {
+		assert.Equal(t, configHash(a.CollectorSpec(component).Config), a.Annotations[DefaultConfigAnnotation(component)])
+		assert.True(t, a.UsesDefaultConfig(component), "The %s should use the default config", component)
+	}
+}
+
+func TestDefaultStopsTrackingUserConfigs(t *testing.T) {
+	var a = Agent{}
+	a.Default()
+
+	a.Spec.Agent.Config = "receivers:\n  otlp:\n"
+	a.Default()
+
+	assert.NotContains(t, a.Annotations, DefaultConfigAnnotation("agent"))
+	assert.False(t, a.UsesDefaultConfig("agent"), "A user provided config shouldn't be tracked as the default config")
+	assert.True(t, a.UsesDefaultConfig("gateway"))
+}
+
+func TestDefaultKeepsTrackingOlderDefaultConfigs(t *testing.T) {
+	olderDefault := "receivers:\n  otlp:\n"
+	var a = Agent{}
+	a.Annotations = map[string]string{DefaultConfigAnnotation("agent"): configHash(olderDefault)}
+	a.Spec.Agent.Config = olderDefault
+	a.Default()
+
+	assert.Equal(t, olderDefault, a.Spec.Agent.Config)
+	assert.True(t, a.UsesDefaultConfig("agent"), "The default config of an older operator should still be tracked")
+}
+
+func TestRefreshDefaultConfig(t *testing.T) {
+	olderDefault := "receivers:\n  otlp:\n"
+	original := map[string]string{DefaultConfigAnnotation("agent"): configHash(olderDefault)}
+	var a = Agent{}
+	a.Annotations = original
+	a.Spec.Agent.Config = olderDefault
+	a.Spec.Gateway.Config = olderDefault
+
+	assert.True(t, a.RefreshDefaultConfig("agent"))
+	assert.Equal(t, defaultAgentConfig, a.Spec.Agent.Config)
+	assert.Equal(t, configHash(defaultAgentConfig), a.Annotations[DefaultConfigAnnotation("agent")])
+	assert.Equal(t, configHash(olderDefault), original[DefaultConfigAnnotation("agent")], "The original annotations shouldn't be changed in place")
+
+	assert.False(t, a.RefreshDefaultConfig("agent"), "An up to date default config shouldn't be refreshed")
+	assert.False(t, a.RefreshDefaultConfig("gateway"), "A user provided config shouldn't be refreshed")
+	assert.Equal(t, olderDefault, a.Spec.Gateway.Config)
+}
+
+func TestDefaultConfigOutdated(t *testing.T) {
+	olderDefault := "receivers:\n  otlp:\n"
+	var a = Agent{}
+	a.Default()
+	a.Annotations[DefaultConfigAnnotation("agent")] = configHash(olderDefault)
+	a.Spec.Agent.Config = olderDefault
+	a.Spec.ClusterReceiver.Config = olderDefault
+
+	assert.True(t, a.DefaultConfigOutdated("agent"), "The default config of another operator should be outdated")
+	assert.False(t, a.DefaultConfigOutdated("cluster-receiver"), "A user provided config shouldn't be outdated")
+	assert.False(t, a.DefaultConfigOutdated("gateway"), "The current default config shouldn't be outdated")
+}
@@ -194,6 +194,7 @@ service:
         - jaeger
         - zipkin
       processors:
+        - memory_limiter
         - k8sattributes
         - batch
         - resource
@@ -208,6 +209,7 @@ service:
         - receiver_creator
         - signalfx
       processors:
+        - memory_limiter
         - batch
         - resource
         - resourcedetection
@@ -217,6 +219,7 @@ service:
       receivers:
         - prometheus/self
       processors:
+        - memory_limiter
         - batch
         - resource
         - resource/self
@@ -303,6 +306,7 @@ service:
       receivers:
         - k8s_cluster
       processors:
+        - memory_limiter
         - batch
         - resource
         - resourcedetection
@@ -312,6 +316,7 @@ service:
       receivers:
         - prometheus/self
       processors:
+        - memory_limiter
         - batch
         - resource
         - resource/self
@@ -397,6 +402,7 @@ service:
       receivers:
         - k8s_cluster
       processors:
+        - memory_limiter
         - batch
         - resource
         - resourcedetection
@@ -406,6 +412,7 @@ service:
       receivers:
         - prometheus/self
       processors:
+        - memory_limiter
         - batch
         - resource
         - resource/self
@@ -594,4 +601,13 @@ service:
 	// the javaagent version is managed by the update-javaagent-version.sh script.
 	defaultJavaAgentVersion = "v1.20.0"
 	defaultJavaAgentImage   = "quay.io/signalfx/splunk-otel-instrumentation-java:" + defaultJavaAgentVersion
+
+	defaultNodeJSAgentVersion = "v2.4.1"
+	defaultNodeJSAgentImage   = "ghcr.io/signalfx/splunk-otel-js/splunk-otel-js:" + defaultNodeJSAgentVersion
+
+	defaultPythonAgentVersion = "v1.11.0"
+	defaultPythonAgentImage   = "ghcr.io/signalfx/splunk-otel-python/splunk-otel-python:" + defaultPythonAgentVersion
+
+	defaultDotNetAgentVersion = "v1.0.0"
+	defaultDotNetAgentImage   = "ghcr.io/signalfx/splunk-otel-dotnet/splunk-otel-dotnet:" + defaultDotNetAgentVersion
 )
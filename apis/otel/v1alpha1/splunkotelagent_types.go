@@ -30,19 +30,28 @@ type CollectorSpec struct {
 
 	// Config is the raw YAML to be used as the collector's configuration. Refer to the OpenTelemetry Collector documentation for details.
 	// This will be automatically set by the operator but can be overridden by the user.
-	// No effort is made to merge the user provided config with the default config set by the operator.
+	// No effort is made to merge the user provided config with the default config set by the operator, use ConfigOverlay to extend the default config instead.
 	// User provided config always overrides the default config.
+	// The default config is refreshed when a newer operator ships a different one, user provided config is left untouched.
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	Config string `json:"config,omitempty"`
 
+	// ConfigOverlay is raw YAML deep-merged onto the Config, so that the default config can be extended without
+	// copying it. Maps are merged key by key and other values replace the ones from the Config, except for the
+	// service.extensions and service.pipelines.*.{receivers,processors,exporters} lists, which are appended to.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ConfigOverlay string `json:"configOverlay,omitempty"`
+
 	// Args is the set of arguments to pass to the OpenTelemetry Collector binary
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	Args map[string]string `json:"args,omitempty"`
 
 	// Replicas is the number of pod instances for the underlying OpenTelemetry Collector.
-	// Only applicable in Gateway mode.
+	// Only applicable in Gateway mode. When unset, or when an HPA scales the gateway, the operator leaves the
+	// replicas of the deployment to others.
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	Replicas *int32 `json:"replicas,omitempty"`
@@ -58,7 +67,8 @@ type CollectorSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	Image string `json:"image,omitempty"`
 
-	// ServiceAccount indicates the name of an existing service account to use with this instance.
+	// ServiceAccount indicates the name of an existing service account to use with this component. The operator
+	// creates one named after the component when it's empty, and binds the cluster role of the component to either.
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	ServiceAccount string `json:"serviceAccount,omitempty"`
@@ -93,6 +103,13 @@ type CollectorSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	Ports []v1.ServicePort `json:"ports,omitempty"`
 
+	// ServiceEnabled determines whether the services exposing the ports of the collector are created.
+	// They're created by default for the gateway only. The agent service routes to the agent of the node the client
+	// runs on, so that applications can send their data to a stable service name instead of the host IP.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ServiceEnabled *bool `json:"serviceEnabled,omitempty"`
+
 	// ENV vars to set on the OpenTelemetry Collector's Pods. These can then in certain cases be
 	// consumed in the config file for the Collector.
 	// Setting this field will override any existing environment variables set by the operator.
@@ -118,6 +135,21 @@ type Instrumentation struct {
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	Java AutoInstrumentation `json:"java,omitempty"`
+
+	// NodeJS is used to configure Node.js SDK and auto-instrumentation agent.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NodeJS AutoInstrumentation `json:"nodejs,omitempty"`
+
+	// Python is used to configure Python SDK and auto-instrumentation agent.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Python AutoInstrumentation `json:"python,omitempty"`
+
+	// DotNet is used to configure .NET SDK and auto-instrumentation agent.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DotNet AutoInstrumentation `json:"dotnet,omitempty"`
 }
 
 type AutoInstrumentation struct {
@@ -125,6 +157,13 @@ type AutoInstrumentation struct {
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	Image string `json:"image,omitempty"`
+
+	// NamespaceSelector selects the namespaces whose pods are instrumented without being annotated, an empty selector
+	// selecting all the namespaces. The system namespaces and the namespace of the operator are never selected. The
+	// `otel.splunk.com/inject-*` annotations of the namespaces and pods take precedence.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
 }
 
 // AgentSpec defines the desired state of SplunkOtelAgent.
@@ -160,20 +199,139 @@ type AgentSpec struct {
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	Gateway CollectorSpec `json:"gateway,omitempty"`
+
+	// AccessToken is where the operator copies the Splunk access token from into the `splunk-access-token` secret read
+	// by the collectors. Only a key of another secret in the namespace of the Agent is supported: secrets of other
+	// namespaces and projected sources can't be referenced. When set, the collectors aren't deployed until the token is
+	// available. When unset, the `splunk-access-token` secret has to be created along with the Agent.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AccessToken *AccessTokenSource `json:"accessToken,omitempty"`
+
+	// DriftPolicy tells what the operator does with the objects it manages when someone else changed the fields it
+	// sets: `enforce` reverts the changes, `report-only` leaves them in place. Drift is reported as events either way.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=enforce;report-only
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
 }
 
+// AccessTokenSource references the Splunk access token the operator syncs into the secret read by the collectors.
+type AccessTokenSource struct {
+	// SecretRef is the secret key holding the token, in the namespace of the Agent. It can't be the
+	// `splunk-access-token` secret itself.
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	SecretRef SecretKeyReference `json:"secretRef"`
+}
+
+// SecretKeyReference references a key of a secret in the namespace of the Agent. Secrets of other namespaces can't be
+// referenced, as it would let whoever can create an Agent read any secret through the operator.
+type SecretKeyReference struct {
+	// Name of the secret.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Key of the token in the secret, `access-token` when empty.
+	// +kubebuilder:validation:Optional
+	Key string `json:"key,omitempty"`
+}
+
+// The secret the collectors read the access token from by default.
+const (
+	AccessTokenSecret    = "splunk-access-token"
+	AccessTokenSecretKey = "access-token"
+)
+
+// DriftPolicy tells how to handle changes made to the managed objects outside of the operator.
+type DriftPolicy string
+
+const (
+	// DriftPolicyEnforce reverts the changes made outside of the operator.
+	DriftPolicyEnforce DriftPolicy = "enforce"
+	// DriftPolicyReportOnly only reports the changes made outside of the operator.
+	DriftPolicyReportOnly DriftPolicy = "report-only"
+)
+
+// Condition types reported in the status of an Agent.
+const (
+	// ConditionReady is true when every enabled component has all its pods ready with the current spec.
+	ConditionReady = "Ready"
+	// ConditionProgressing is true while the pods of a component are being rolled out.
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded is true when the operator failed to reconcile the instance.
+	ConditionDegraded = "Degraded"
+	// ConditionConfigValid is true when the configs of all the enabled components are valid.
+	ConditionConfigValid = "ConfigValid"
+	// ConditionAccessTokenFound is true when the secrets holding the access token of the components exist.
+	ConditionAccessTokenFound = "AccessTokenFound"
+	// ConditionAccessTokenMissing is true while the collectors wait for the access token synced by the operator.
+	ConditionAccessTokenMissing = "AccessTokenMissing"
+	// ConditionPaused is true when the reconciliation of the instance, or of some of its components, is paused.
+	ConditionPaused = "Paused"
+	// ConditionTerminating is true while the objects of a deleted instance that can't be garbage collected are deleted.
+	ConditionTerminating = "Terminating"
+)
+
+// MaxStatusMessages is the number of messages kept in the status, older messages are dropped first.
+const MaxStatusMessages = 20
+
 // AgentStatus defines the observed state of SplunkOtelAgent.
 type AgentStatus struct {
 	// Version of the managed OpenTelemetry Collector (operand).
 	Version string `json:"version,omitempty"`
 
-	// Messages about actions performed by the operator on this resource.
+	// ObservedGeneration is the generation of the spec the status was computed for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest observations of the state of the instance.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Components holds the pod counts of each enabled component.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Components []ComponentStatus `json:"components,omitempty"`
+
+	// Messages about actions performed by the operator on this resource. Only the most recent ones are kept.
 	// +listType=atomic
 	Messages []string `json:"messages,omitempty"`
 }
 
+// ComponentStatus defines the observed state of the workload of a component.
+type ComponentStatus struct {
+	// Name of the component, like "agent".
+	Name string `json:"name"`
+
+	// Desired is the number of pods that should be running.
+	Desired int32 `json:"desired"`
+
+	// Ready is the number of pods that are ready.
+	Ready int32 `json:"ready"`
+
+	// Updated is the number of pods running with the current spec.
+	Updated int32 `json:"updated"`
+}
+
+// AddMessages appends messages to the status, dropping the oldest ones beyond MaxStatusMessages.
+func (s *AgentStatus) AddMessages(messages ...string) {
+	s.Messages = append(s.Messages, messages...)
+	if len(s.Messages) > MaxStatusMessages {
+		s.Messages = s.Messages[len(s.Messages)-MaxStatusMessages:]
+	}
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+// +kubebuilder:printcolumn:name="Agents",type="integer",JSONPath=".status.components[?(@.name==\"agent\")].ready",description="Ready agent pods"
+// +kubebuilder:printcolumn:name="Gateways",type="integer",JSONPath=".status.components[?(@.name==\"gateway\")].ready",description="Ready gateway pods",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.version",description="Splunk OpenTelemetry Operator Version"
 // +operator-sdk:csv:customresourcedefinitions:displayName="Splunk OpenTelemetry Collector"
@@ -0,0 +1,36 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddMessagesKeepsTheMostRecent(t *testing.T) {
+	var status AgentStatus
+	for i := 0; i < MaxStatusMessages; i++ {
+		status.AddMessages(fmt.Sprintf("message %d", i))
+	}
+	assert.Len(t, status.Messages, MaxStatusMessages)
+
+	status.AddMessages("newer", "newest")
+
+	assert.Len(t, status.Messages, MaxStatusMessages)
+	assert.Equal(t, "message 2", status.Messages[0])
+	assert.Equal(t, "newest", status.Messages[MaxStatusMessages-1])
+}
@@ -0,0 +1,84 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// validatingHandler serves the validating webhook for Agents, like the controller-runtime handler for
+// webhook.Validator does, but returning the warnings of the validation along with the admission response.
+type validatingHandler struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &validatingHandler{}
+
+// InjectDecoder injects the decoder into the validatingHandler.
+func (h *validatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	return nil
+}
+
+// Handle validates the Agent of the admission request.
+func (h *validatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	agent := &Agent{}
+
+	var warnings Warnings
+	var err error
+	switch req.Operation {
+	case admissionv1.Create:
+		if err := h.decoder.Decode(req, agent); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		warnings, err = agent.ValidateCreate()
+
+	case admissionv1.Update:
+		old := &Agent{}
+		if err := h.decoder.Decode(req, agent); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := h.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		warnings, err = agent.ValidateUpdate(old)
+
+	case admissionv1.Delete:
+		// the object being deleted is only available as the old object
+		if err := h.decoder.DecodeRaw(req.OldObject, agent); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		warnings, err = agent.ValidateDelete()
+	}
+
+	if err != nil {
+		var apiStatus apierrors.APIStatus
+		if errors.As(err, &apiStatus) {
+			status := apiStatus.Status()
+			return admission.Response{AdmissionResponse: admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &status,
+			}}.WithWarnings(warnings...)
+		}
+		return admission.Denied(err.Error()).WithWarnings(warnings...)
+	}
+	return admission.Allowed("").WithWarnings(warnings...)
+}
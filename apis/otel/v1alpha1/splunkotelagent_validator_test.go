@@ -0,0 +1,76 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestValidatingHandlerReturnsWarnings(t *testing.T) {
+	// prepare
+	scheme := runtime.NewScheme()
+	require.NoError(t, AddToScheme(scheme))
+	decoder, err := admission.NewDecoder(scheme)
+	require.NoError(t, err)
+
+	h := &validatingHandler{}
+	require.NoError(t, h.InjectDecoder(decoder))
+
+	request := func(a Agent) admission.Request {
+		a.APIVersion = GroupVersion.String()
+		a.Kind = "Agent"
+		raw, err := json.Marshal(a)
+		require.NoError(t, err)
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}}
+	}
+
+	a := Agent{}
+	a.Default()
+	a.Spec.Agent.ConfigOverlay = `
+exporters:
+  debug:
+service:
+  pipelines:
+    traces:
+      exporters: [debug]
+`
+
+	// test
+	res := h.Handle(context.Background(), request(a))
+
+	// verify
+	assert.True(t, res.Allowed)
+	assert.Len(t, res.Warnings, 1)
+
+	// test
+	a.Spec.ClusterReceiver.HostNetwork = true
+	res = h.Handle(context.Background(), request(a))
+
+	// verify
+	assert.False(t, res.Allowed)
+	assert.EqualValues(t, "`hostNetwork` cannot be true for the clusterReceiver", res.Result.Reason)
+	assert.Len(t, res.Warnings, 1, "Warnings should be returned along with denials")
+}
@@ -15,18 +15,25 @@
 package v1alpha1
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/signalfx/splunk-otel-collector-operator/internal/autodetect"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/adapters"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/catalog"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/version"
 )
 
 // log is for logging in this package.
@@ -36,9 +43,16 @@ var detectedDistro autodetect.Distro = autodetect.UnknownDistro
 
 func (r *Agent) SetupWebhookWithManager(mgr ctrl.Manager, distro autodetect.Distro) error {
 	detectedDistro = distro
-	return ctrl.NewWebhookManagedBy(mgr).
+	err := ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
+	if err != nil {
+		return err
+	}
+
+	// the builder only knows about validators without warnings, so the validating webhook is registered by hand
+	mgr.GetWebhookServer().Register(validatingWebhookPath, &webhook.Admission{Handler: &validatingHandler{}})
+	return nil
 }
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -58,6 +72,10 @@ func (r *Agent) Default() {
 		r.Labels["app.kubernetes.io/managed-by"] = "splunk-otel-collector-operator"
 	}
 
+	if r.Spec.DriftPolicy == "" {
+		r.Spec.DriftPolicy = DriftPolicyEnforce
+	}
+
 	r.defaultInstrumentation()
 	r.defaultAgent()
 	r.defaultClusterReceiver()
@@ -67,27 +85,49 @@ func (r *Agent) Default() {
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
 // +kubebuilder:webhook:path=/validate-otel-splunk-com-v1alpha1-agent,mutating=false,failurePolicy=fail,sideEffects=None,groups=otel.splunk.com,resources=agents,verbs=create;update,versions=v1alpha1,name=vagent.kb.io,admissionReviewVersions={v1,v1beta1}
 
-var _ webhook.Validator = &Agent{}
+const validatingWebhookPath = "/validate-otel-splunk-com-v1alpha1-agent"
 
-// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
-func (r *Agent) ValidateCreate() error {
+// Warnings are non-blocking messages returned to the API client along with an admission response.
+// +kubebuilder:object:generate=false
+type Warnings []string
+
+// ValidateCreate validates the Agent on creation, returning warnings for risky settings that are still allowed.
+func (r *Agent) ValidateCreate() (Warnings, error) {
 	agentlog.Info("validate create", "name", r.Name)
-	return r.validateCRDSpec()
+	return r.warnings(Components), r.validateCRDSpec(Components)
 }
 
-// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
-func (r *Agent) ValidateUpdate(old runtime.Object) error {
+// ValidateUpdate validates the Agent on update, returning warnings for risky settings that are still allowed. Only the
+// configs of the components whose spec changed are validated: Agents stored before the current rules are still updated
+// by the operator, like to add or remove its finalizer or to upgrade their configs, which mustn't be blocked by the
+// configs it doesn't change.
+func (r *Agent) ValidateUpdate(old runtime.Object) (Warnings, error) {
 	agentlog.Info("validate update", "name", r.Name)
-	return r.validateCRDSpec()
+	previous, ok := old.(*Agent)
+	if !ok || previous == nil {
+		return r.warnings(Components), r.validateCRDSpec(Components)
+	}
+	if equality.Semantic.DeepEqual(r.Spec, previous.Spec) {
+		return nil, nil
+	}
+
+	var changed []string
+	for _, component := range Components {
+		if !equality.Semantic.DeepEqual(r.CollectorSpec(component), previous.CollectorSpec(component)) {
+			changed = append(changed, component)
+		}
+	}
+	return r.warnings(changed), r.validateCRDSpec(changed)
 }
 
-// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
-func (r *Agent) ValidateDelete() error {
+// ValidateDelete validates the Agent on deletion.
+func (r *Agent) ValidateDelete() (Warnings, error) {
 	agentlog.Info("validate delete", "name", r.Name)
-	return nil
+	return nil, nil
 }
 
-func (r *Agent) validateCRDSpec() error {
+// validateCRDSpec validates the spec, including the configs of the given components.
+func (r *Agent) validateCRDSpec(components []string) error {
 	var errs []string
 
 	if err := r.validateInstrumentation(); err != nil {
@@ -105,13 +145,44 @@ func (r *Agent) validateCRDSpec() error {
 	if err := r.validateCRDGatewaySpec(); err != nil {
 		errs = append(errs, err.Error())
 	}
+
+	if err := r.validateAccessToken(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if err := r.validateConfigs(components); err != nil {
+		errs = append(errs, err.Error())
+	}
 	if len(errs) > 0 {
 		return fmt.Errorf(strings.Join(errs, "\n"))
 	}
 	return nil
 }
 
+// imageReference matches container image references, like "ghcr.io/signalfx/splunk-otel-python:v1.11.0", with an
+// optional registry, tag and digest.
+var imageReference = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?/)?` +
+	`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+	`(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?(?:@sha256:[a-f0-9]{64})?$`)
+
 func (r *Agent) validateInstrumentation() error {
+	for _, agent := range []struct {
+		name string
+		spec AutoInstrumentation
+	}{
+		{"java", r.Spec.Instrumentation.Java},
+		{"nodejs", r.Spec.Instrumentation.NodeJS},
+		{"python", r.Spec.Instrumentation.Python},
+		{"dotnet", r.Spec.Instrumentation.DotNet},
+	} {
+		if agent.spec.Image != "" && !imageReference.MatchString(agent.spec.Image) {
+			return fmt.Errorf("`instrumentation.%s.image` is not a valid image reference: %q", agent.name, agent.spec.Image)
+		}
+		if _, err := metav1.LabelSelectorAsSelector(agent.spec.NamespaceSelector); err != nil {
+			return fmt.Errorf("`instrumentation.%s.namespaceSelector` is invalid: %w", agent.name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -149,10 +220,120 @@ func (r *Agent) validateCRDGatewaySpec() error {
 	return nil
 }
 
+func (r *Agent) validateAccessToken() error {
+	if r.Spec.AccessToken == nil {
+		return nil
+	}
+
+	if r.Spec.AccessToken.SecretRef.Name == AccessTokenSecret {
+		return fmt.Errorf("`accessToken.secretRef.name` cannot be %q, the secret the token is copied into", AccessTokenSecret)
+	}
+
+	return nil
+}
+
+// ValidateConfigs checks the configs of the components, as merged with their overlays, reporting every invalid config
+// in the returned error. It's used by the webhook and to report the ConfigValid condition.
+func (r *Agent) ValidateConfigs() error {
+	return r.validateConfigs(Components)
+}
+
+func (r *Agent) validateConfigs(components []string) error {
+	var errs []string
+	for _, component := range components {
+		spec := r.CollectorSpec(component)
+		if spec.Config == "" && spec.ConfigOverlay == "" {
+			continue
+		}
+
+		configStr, err := adapters.ConfigWithOverlay(spec.Config, spec.ConfigOverlay)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("`configOverlay` of the %s can't be merged onto its `config`: %v", component, err))
+			continue
+		}
+
+		config, err := adapters.ConfigFromString(configStr)
+		if err == nil {
+			err = adapters.ValidateConfig(config)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("`config` of the %s is invalid: %v", component, err))
+			continue
+		}
+		if rbacErr := adapters.ValidateRBAC(config); rbacErr != nil {
+			errs = append(errs, fmt.Sprintf("`config` of the %s needs permissions the operator doesn't grant: %v", component, rbacErr))
+		}
+
+		// custom images and releases missing from the catalog may ship any component
+		release, ok := collectorRelease(spec)
+		if !ok {
+			continue
+		}
+		if shipped, ok := catalog.For(release); ok {
+			if missing := shipped.Missing(config); len(missing) > 0 {
+				errs = append(errs, fmt.Sprintf("`config` of the %s uses components not shipped with the Splunk OpenTelemetry Collector %s: %s", component, release, strings.Join(missing, ", ")))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// collectorRelease returns the release of the collector image the given spec runs, when it can be told from the image.
+func collectorRelease(spec *CollectorSpec) (string, bool) {
+	if spec.Image == "" {
+		return version.Collector(), true
+	}
+	return catalog.ReleaseOf(spec.Image)
+}
+
+// warnings runs the config warning rules over the config of each of the given components that is enabled.
+func (r *Agent) warnings(components []string) Warnings {
+	var warnings Warnings
+	for _, component := range components {
+		spec := r.CollectorSpec(component)
+		if spec.Enabled != nil && !*spec.Enabled {
+			continue
+		}
+
+		// invalid configs are reported by ValidateConfigs
+		configStr, err := adapters.ConfigWithOverlay(spec.Config, spec.ConfigOverlay)
+		if err != nil {
+			continue
+		}
+		config, err := adapters.ConfigFromString(configStr)
+		if err != nil {
+			continue
+		}
+
+		release, _ := collectorRelease(spec)
+		opts := adapters.WarningOptions{
+			CollectorVersion: release,
+			HostNetwork:      spec.HostNetwork,
+		}
+		for _, warning := range adapters.ConfigWarnings(config, opts) {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", component, warning))
+		}
+	}
+	return warnings
+}
+
 func (r *Agent) defaultInstrumentation() {
 	if r.Spec.Instrumentation.Java.Image == "" {
 		r.Spec.Instrumentation.Java.Image = defaultJavaAgentImage
 	}
+	if r.Spec.Instrumentation.NodeJS.Image == "" {
+		r.Spec.Instrumentation.NodeJS.Image = defaultNodeJSAgentImage
+	}
+	if r.Spec.Instrumentation.Python.Image == "" {
+		r.Spec.Instrumentation.Python.Image = defaultPythonAgentImage
+	}
+	if r.Spec.Instrumentation.DotNet.Image == "" {
+		r.Spec.Instrumentation.DotNet.Image = defaultDotNetAgentImage
+	}
 }
 
 func (r *Agent) defaultAgent() {
@@ -164,6 +345,11 @@ func (r *Agent) defaultAgent() {
 		spec.Enabled = &[]bool{true}[0]
 	}
 
+	// The agent service is opt-in, applications usually send to the agent of their node through the host IP
+	if spec.ServiceEnabled == nil {
+		spec.ServiceEnabled = &[]bool{false}[0]
+	}
+
 	if spec.Volumes == nil {
 		spec.Volumes = []v1.Volume{
 			{
@@ -225,8 +411,9 @@ func (r *Agent) defaultAgent() {
 	setDefaultEnvVars(spec, r.Spec.Realm, r.Spec.ClusterName)
 
 	if spec.Config == "" {
-		spec.Config = defaultAgentConfig
+		spec.Config = defaultConfigFor("agent")
 	}
+	r.trackDefaultConfig("agent")
 }
 
 func (r *Agent) defaultClusterReceiver() {
@@ -238,17 +425,18 @@ func (r *Agent) defaultClusterReceiver() {
 		spec.Enabled = &[]bool{true}[0]
 	}
 
+	if spec.ServiceEnabled == nil {
+		spec.ServiceEnabled = &[]bool{false}[0]
+	}
+
 	setDefaultResources(spec, defaultClusterReceiverCPU,
 		defaultClusterReceiverMemory)
 	setDefaultEnvVars(spec, r.Spec.Realm, r.Spec.ClusterName)
 
 	if spec.Config == "" {
-		if detectedDistro == autodetect.OpenShiftDistro {
-			spec.Config = defaultClusterReceiverConfigOpenshift
-		} else {
-			spec.Config = defaultClusterReceiverConfig
-		}
+		spec.Config = defaultConfigFor("cluster-receiver")
 	}
+	r.trackDefaultConfig("cluster-receiver")
 }
 
 func (r *Agent) defaultGateway() {
@@ -261,9 +449,9 @@ func (r *Agent) defaultGateway() {
 		spec.Enabled = &s
 	}
 
-	if spec.Replicas == nil {
-		s := int32(3)
-		spec.Replicas = &s
+	// The gateway is exposed by default, as applications and agents send their data to it
+	if spec.ServiceEnabled == nil {
+		spec.ServiceEnabled = &[]bool{true}[0]
 	}
 
 	if spec.Ports == nil {
@@ -279,6 +467,7 @@ func (r *Agent) defaultGateway() {
 				Port:     4318,
 			},
 			{
+				Name:     "otlp-http-old",
 				Protocol: "TCP",
 				Port:     55681,
 			},
@@ -315,11 +504,6 @@ func (r *Agent) defaultGateway() {
 		spec.Enabled = &s
 	}
 
-	if spec.Replicas == nil {
-		s := int32(3)
-		spec.Replicas = &s
-	}
-
 	if spec.Ports == nil {
 		spec.Ports = []v1.ServicePort{
 			{
@@ -333,6 +517,7 @@ func (r *Agent) defaultGateway() {
 				Port:     4318,
 			},
 			{
+				Name:     "otlp-http-old",
 				Protocol: "TCP",
 				Port:     55681,
 			},
@@ -368,8 +553,9 @@ func (r *Agent) defaultGateway() {
 	setDefaultEnvVars(spec, r.Spec.Realm, r.Spec.ClusterName)
 
 	if spec.Config == "" {
-		spec.Config = defaultGatewayConfig
+		spec.Config = defaultConfigFor("gateway")
 	}
+	r.trackDefaultConfig("gateway")
 }
 
 func setDefaultResources(spec *CollectorSpec, defaultCPU string,
@@ -396,8 +582,8 @@ func setDefaultEnvVars(spec *CollectorSpec, realm string, clusterName string) {
 				Name: "SPLUNK_ACCESS_TOKEN",
 				ValueFrom: &v1.EnvVarSource{
 					SecretKeyRef: &v1.SecretKeySelector{
-						LocalObjectReference: v1.LocalObjectReference{Name: "splunk-access-token"},
-						Key:                  "access-token",
+						LocalObjectReference: v1.LocalObjectReference{Name: AccessTokenSecret},
+						Key:                  AccessTokenSecretKey,
 					},
 				},
 			},
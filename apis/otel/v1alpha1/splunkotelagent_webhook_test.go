@@ -19,7 +19,9 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"strings"
 	"testing"
 )
 
@@ -41,6 +43,10 @@ func TestDefaultAgentValues(t *testing.T) {
 	assert.True(t, *a.Spec.ClusterReceiver.Enabled, "The cluster receiver should be enabled by default")
 	assert.False(t, *a.Spec.Gateway.Enabled, "The gateway should not be enabled by default")
 	assert.Equal(t, a.Spec.Instrumentation.Java.Image, defaultJavaAgentImage, "The java image should have a default value")
+	assert.Equal(t, a.Spec.Instrumentation.NodeJS.Image, defaultNodeJSAgentImage, "The nodejs image should have a default value")
+	assert.Equal(t, a.Spec.Instrumentation.Python.Image, defaultPythonAgentImage, "The python image should have a default value")
+	assert.Equal(t, a.Spec.Instrumentation.DotNet.Image, defaultDotNetAgentImage, "The dotnet image should have a default value")
+	assert.Equal(t, DriftPolicyEnforce, a.Spec.DriftPolicy, "Drift should be reverted by default")
 }
 
 func TestDefaultResourceLimits(t *testing.T) {
@@ -113,3 +119,193 @@ func TestGetMemSizeInMiB(t *testing.T) {
 		assert.Equal(t, getMemSizeInMiB(resource.MustParse(c.in)), c.out)
 	}
 }
+
+func TestValidateConfigOverlay(t *testing.T) {
+	var a = Agent{}
+	a.Default()
+	a.Spec.Agent.ConfigOverlay = `
+receivers:
+  prometheus/app:
+    config:
+      scrape_configs: []
+service:
+  pipelines:
+    metrics:
+      receivers: [prometheus/app]
+`
+	_, err := a.ValidateCreate()
+	assert.NoError(t, err)
+
+	a.Spec.Gateway.ConfigOverlay = `
+service:
+  pipelines:
+    traces:
+      receivers: otlp
+`
+	_, err = a.ValidateCreate()
+	assert.EqualError(t, err, "`configOverlay` of the gateway can't be merged onto its `config`: couldn't merge \"service.pipelines.traces.receivers\", the overlay value isn't a list")
+
+	a.Spec.Gateway.ConfigOverlay = "🦄"
+	_, err = a.ValidateCreate()
+	assert.EqualError(t, err, "`configOverlay` of the gateway can't be merged onto its `config`: couldn't parse the config overlay: couldn't parse the splunk-otel-collector configuration")
+}
+
+func TestValidateInstrumentation(t *testing.T) {
+	var a = Agent{}
+	a.Default()
+	_, err := a.ValidateCreate()
+	assert.NoError(t, err, "The default images should be valid")
+
+	for _, image := range []string{
+		"splunk-otel-python",
+		"localhost:5000/splunk/splunk-otel-python:v1.11.0",
+		"ghcr.io/signalfx/splunk-otel-python/splunk-otel-python@sha256:" + strings.Repeat("a", 64),
+	} {
+		a.Spec.Instrumentation.Python.Image = image
+		_, err = a.ValidateCreate()
+		assert.NoError(t, err, image)
+	}
+
+	a.Spec.Instrumentation.Python.Image = "ghcr.io/signalfx/Splunk-OTel-Python:v1.11.0"
+	_, err = a.ValidateCreate()
+	assert.EqualError(t, err, "`instrumentation.python.image` is not a valid image reference: \"ghcr.io/signalfx/Splunk-OTel-Python:v1.11.0\"")
+
+	a.Spec.Instrumentation.Python.Image = ""
+	a.Spec.Instrumentation.Java.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"instrumentation": "java"}}
+	_, err = a.ValidateCreate()
+	assert.NoError(t, err)
+
+	a.Spec.Instrumentation.Java.NamespaceSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+		{Key: "instrumentation", Operator: "Matches"},
+	}}
+	_, err = a.ValidateCreate()
+	assert.EqualError(t, err, "`instrumentation.java.namespaceSelector` is invalid: \"Matches\" is not a valid pod selector operator")
+}
+
+func TestValidateAccessToken(t *testing.T) {
+	var a = Agent{}
+	a.Default()
+	a.Spec.AccessToken = &AccessTokenSource{SecretRef: SecretKeyReference{Name: "splunk-token", Key: "token"}}
+	_, err := a.ValidateCreate()
+	assert.NoError(t, err)
+
+	a.Spec.AccessToken.SecretRef.Name = AccessTokenSecret
+	_, err = a.ValidateCreate()
+	assert.EqualError(t, err, "`accessToken.secretRef.name` cannot be \"splunk-access-token\", the secret the token is copied into")
+
+	old := a.DeepCopy()
+	old.Spec.AccessToken.SecretRef.Name = "splunk-token"
+	_, err = a.ValidateUpdate(old)
+	assert.EqualError(t, err, "`accessToken.secretRef.name` cannot be \"splunk-access-token\", the secret the token is copied into")
+}
+
+func TestValidateConfig(t *testing.T) {
+	var a = Agent{}
+	a.Default()
+	assert.NoError(t, a.ValidateConfigs(), "The default configs should be valid")
+
+	a.Spec.Agent.Config = "🦄"
+	a.Spec.ClusterReceiver.Config = `
+receivers:
+  k8s_cluster:
+service:
+  pipelines:
+    metrics:
+      receivers: [k8s_cluster]
+      exporters: [signalfx]
+`
+	_, err := a.ValidateUpdate(nil)
+	assert.EqualError(t, err, "`config` of the agent is invalid: couldn't parse the splunk-otel-collector configuration\n"+
+		"`config` of the cluster-receiver is invalid: \"service.pipelines.metrics.exporters\" references the undefined exporter \"signalfx\"")
+}
+
+func TestValidateUpdateOfStoredConfigs(t *testing.T) {
+	// an Agent stored before its config was rejected
+	var old = Agent{}
+	old.Default()
+	old.Spec.Agent.Config = "🦄"
+
+	a := old.DeepCopy()
+	a.Finalizers = append(a.Finalizers, "otel.splunk.com/cleanup")
+	_, err := a.ValidateUpdate(&old)
+	assert.NoError(t, err, "Metadata changes shouldn't validate the configs")
+
+	a.Spec.Gateway.Config = strings.Replace(a.Spec.Gateway.Config, "13133", "13134", 1)
+	_, err = a.ValidateUpdate(&old)
+	assert.NoError(t, err, "Only the configs of the changed components should be validated")
+
+	a.Spec.Agent.Config = "🦄🦄"
+	_, err = a.ValidateUpdate(&old)
+	assert.EqualError(t, err, "`config` of the agent is invalid: couldn't parse the splunk-otel-collector configuration")
+}
+
+func TestValidateK8sObjectsAccess(t *testing.T) {
+	var a = Agent{}
+	a.Default()
+	a.Spec.ClusterReceiver.Image = "registry.example.com/custom-collector:0.71.0"
+	a.Spec.ClusterReceiver.ConfigOverlay = `
+receivers:
+  k8sobjects:
+    objects:
+      - name: pods
+      - name: configmaps
+      - name: customresourcedefinitions
+        group: apiextensions.k8s.io
+service:
+  pipelines:
+    logs:
+      receivers: [k8sobjects]
+      exporters: [signalfx]
+`
+	_, err := a.ValidateCreate()
+	assert.EqualError(t, err, "`config` of the cluster-receiver needs permissions the operator doesn't grant: "+
+		"the \"k8sobjects\" receiver can't be granted access to configmaps, customresourcedefinitions.apiextensions.k8s.io")
+}
+
+func TestValidateWarnings(t *testing.T) {
+	var a = Agent{}
+	a.Default()
+	warnings, err := a.ValidateCreate()
+	assert.NoError(t, err)
+	assert.Empty(t, warnings, "The default configs shouldn't raise warnings")
+
+	a.Spec.Agent.ConfigOverlay = `
+exporters:
+  debug:
+service:
+  pipelines:
+    traces:
+      exporters: [debug]
+`
+	a.Spec.Gateway.ConfigOverlay = a.Spec.Agent.ConfigOverlay
+	warnings, err = a.ValidateUpdate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Warnings{
+		"agent: pipeline \"traces\" exports to \"debug\", which writes all the telemetry to the collector logs and is meant for troubleshooting",
+	}, warnings, "Disabled components shouldn't raise warnings")
+}
+
+func TestValidateComponentsShippedWithImage(t *testing.T) {
+	var a = Agent{}
+	a.Default()
+	for _, component := range Components {
+		a.CollectorSpec(component).Image = "quay.io/signalfx/splunk-otel-collector:0.71.0"
+	}
+	_, err := a.ValidateCreate()
+	assert.NoError(t, err, "The default configs should only use components shipped with the collector")
+
+	a.Spec.Agent.ConfigOverlay = `
+receivers:
+  awsxray:
+service:
+  pipelines:
+    traces:
+      receivers: [awsxray]
+`
+	_, err = a.ValidateCreate()
+	assert.EqualError(t, err, "`config` of the agent uses components not shipped with the Splunk OpenTelemetry Collector 0.71.0: receiver \"awsxray\"")
+
+	a.Spec.Agent.Image = "registry.example.com/custom-collector:0.71.0"
+	_, err = a.ValidateCreate()
+	assert.NoError(t, err, "Custom images may ship any component")
+}
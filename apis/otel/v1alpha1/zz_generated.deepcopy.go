@@ -21,9 +21,26 @@ package v1alpha1
 
 import (
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessTokenSource) DeepCopyInto(out *AccessTokenSource) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessTokenSource.
+func (in *AccessTokenSource) DeepCopy() *AccessTokenSource {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessTokenSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Agent) DeepCopyInto(out *Agent) {
 	*out = *in
@@ -86,10 +103,15 @@ func (in *AgentList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AgentSpec) DeepCopyInto(out *AgentSpec) {
 	*out = *in
-	out.Instrumentation = in.Instrumentation
+	in.Instrumentation.DeepCopyInto(&out.Instrumentation)
 	in.Agent.DeepCopyInto(&out.Agent)
 	in.ClusterReceiver.DeepCopyInto(&out.ClusterReceiver)
 	in.Gateway.DeepCopyInto(&out.Gateway)
+	if in.AccessToken != nil {
+		in, out := &in.AccessToken, &out.AccessToken
+		*out = new(AccessTokenSource)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentSpec.
@@ -105,6 +127,18 @@ func (in *AgentSpec) DeepCopy() *AgentSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AgentStatus) DeepCopyInto(out *AgentStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]ComponentStatus, len(*in))
+		copy(*out, *in)
+	}
 	if in.Messages != nil {
 		in, out := &in.Messages, &out.Messages
 		*out = make([]string, len(*in))
@@ -125,6 +159,11 @@ func (in *AgentStatus) DeepCopy() *AgentStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AutoInstrumentation) DeepCopyInto(out *AutoInstrumentation) {
 	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoInstrumentation.
@@ -183,6 +222,11 @@ func (in *CollectorSpec) DeepCopyInto(out *CollectorSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ServiceEnabled != nil {
+		in, out := &in.ServiceEnabled, &out.ServiceEnabled
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]v1.EnvVar, len(*in))
@@ -210,10 +254,28 @@ func (in *CollectorSpec) DeepCopy() *CollectorSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentStatus) DeepCopyInto(out *ComponentStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentStatus.
+func (in *ComponentStatus) DeepCopy() *ComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Instrumentation) DeepCopyInto(out *Instrumentation) {
 	*out = *in
-	out.Java = in.Java
+	in.Java.DeepCopyInto(&out.Java)
+	in.NodeJS.DeepCopyInto(&out.NodeJS)
+	in.Python.DeepCopyInto(&out.Python)
+	in.DotNet.DeepCopyInto(&out.DotNet)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Instrumentation.
@@ -225,3 +287,18 @@ func (in *Instrumentation) DeepCopy() *Instrumentation {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -18,29 +18,50 @@ package otel
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
 	otelv1alpha1 "github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/reconcile"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/upgrade"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/version"
 )
 
-// Task represents a reconciliation task to be executed by the reconciler.
+// Task represents a reconciliation task to be executed by the reconciler. Tasks the following ones depend on bail
+// on error, the others let the remaining tasks run when they fail.
 type Task struct {
 	Name        string
 	Do          func(context.Context, reconcile.Params) error
 	BailOnError bool
 }
 
+// the delay before reconciling an instance with failed tasks again doubles with each consecutive failure of a task
+const (
+	minTaskBackoff = 5 * time.Second
+	maxTaskBackoff = 5 * time.Minute
+)
+
+// cleanupPollInterval is the delay between two checks of the objects a deleted instance waits for.
+const cleanupPollInterval = 2 * time.Second
+
 // SplunkOtelAgentReconciler reconciles a SplunkOtelAgent object.
 type SplunkOtelAgentReconciler struct {
 	client.Client
@@ -48,18 +69,15 @@ type SplunkOtelAgentReconciler struct {
 	scheme   *runtime.Scheme
 	recorder record.EventRecorder
 	tasks    []Task
+
+	// failures counts the consecutive failures of each task, per instance
+	failuresMu sync.Mutex
+	failures   map[types.NamespacedName]map[string]int
 }
 
 // NewReconciler creates a new reconciler for SplunkOtelAgent objects.
 func NewReconciler(logger logr.Logger, client client.Client, scheme *runtime.Scheme, recorder record.EventRecorder) *SplunkOtelAgentReconciler {
 	tasks := []Task{
-		// TODO(splunk): see if we should handle creation of the namespace as well
-		// this is tricky as,
-		//   - access token secret needs to be in the same namespace
-		//   - if we add namespace here then agents will be started before user creates secret
-		//   - this means agent will crash until the secret is not created
-		// the can lead to confusing behavior so it might be better to have the user
-		// create namespace and secret both before creating SplunkOtelAgent
 		{
 			"config maps",
 			reconcile.ConfigMaps,
@@ -70,30 +88,55 @@ func NewReconciler(logger logr.Logger, client client.Client, scheme *runtime.Sch
 			reconcile.ServiceAccounts,
 			true,
 		},
+		{
+			"access tokens",
+			reconcile.AccessTokens,
+			false,
+		},
+		{
+			"cluster roles",
+			reconcile.ClusterRoles,
+			false,
+		},
+		{
+			"cluster role bindings",
+			reconcile.ClusterRoleBindings,
+			false,
+		},
+		{
+			"roles",
+			reconcile.Roles,
+			false,
+		},
+		{
+			"role bindings",
+			reconcile.RoleBindings,
+			false,
+		},
 		{
 			"services",
 			reconcile.Services,
-			true,
+			false,
 		},
 		{
 			"cluster receiver",
 			reconcile.ClusterReceivers,
-			true,
+			false,
 		},
 		{
 			"agent",
 			reconcile.Agents,
-			true,
+			false,
 		},
 		{
 			"gateway",
 			reconcile.Gateways,
-			true,
+			false,
 		},
 		{
 			"splunk opentelemetry",
 			reconcile.Self,
-			true,
+			false,
 		},
 	}
 
@@ -103,12 +146,14 @@ func NewReconciler(logger logr.Logger, client client.Client, scheme *runtime.Sch
 		scheme:   scheme,
 		recorder: recorder,
 		tasks:    tasks,
+		failures: map[types.NamespacedName]map[string]int{},
 	}
 }
 
 //+kubebuilder:rbac:groups=otel.splunk.com,resources=agents,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=otel.splunk.com,resources=agents/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=otel.splunk.com,resources=agents/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -132,6 +177,30 @@ func (r *SplunkOtelAgentReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, log, instance)
+	}
+
+	// cluster-scoped objects aren't garbage collected along with the instance, the finalizer lets them be deleted first
+	if !controllerutil.ContainsFinalizer(&instance, reconcile.Finalizer) {
+		patch := client.MergeFromWithOptions(instance.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		controllerutil.AddFinalizer(&instance, reconcile.Finalizer)
+		if err := r.Patch(ctx, &instance, patch); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to add the finalizer: %w", err)
+		}
+	}
+
+	// instances created by an older operator, or still running the default config of another operator, have to go
+	// through the upgrade routine before being reconciled, otherwise the workloads would be deployed with a
+	// configuration the current collector might not accept
+	if !instance.Paused() && upgrade.Outdated(instance, version.Get()) {
+		upgraded, err := upgrade.Instance(ctx, log, version.Get(), r.Client, r.recorder, instance)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to upgrade the instance: %w", err)
+		}
+		instance = upgraded
+	}
+
 	params := reconcile.Params{
 		Client:   r.Client,
 		Instance: instance,
@@ -140,35 +209,222 @@ func (r *SplunkOtelAgentReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		Recorder: r.recorder,
 	}
 
-	if err := r.RunTasks(ctx, params); err != nil {
+	// paused instances are left as they are, only their status is refreshed
+	var failures []reconcile.TaskFailure
+	var err error
+	if instance.Paused() {
+		log.V(1).Info("reconciliation paused", "annotation", v1alpha1.AnnotationReconcile)
+	} else {
+		failures, err = r.RunTasks(ctx, params)
+	}
+	for _, failure := range failures {
+		r.event(&instance, corev1.EventTypeWarning, failure.Reason(), failure.Error())
+	}
+
+	// the status reports failed reconciliations as well, so it's updated whatever the outcome of the tasks
+	if statusErr := reconcile.UpdateStatus(ctx, params, failures); statusErr != nil {
+		log.Error(statusErr, "failed to update the status")
+		if err == nil {
+			err = statusErr
+		}
+	}
+
+	backoff := r.backoff(req.NamespacedName, failures)
+	if err != nil {
+		// the work queue retries with its own exponential backoff
 		return ctrl.Result{}, err
 	}
+	return ctrl.Result{RequeueAfter: backoff}, nil
+}
+
+// finalize deletes the objects of the deleted instance that can't be garbage collected, reporting the ones it's waiting
+// for in the status, and removes the finalizer once they're all gone.
+func (r *SplunkOtelAgentReconciler) finalize(ctx context.Context, log logr.Logger, instance v1alpha1.Agent) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(&instance, reconcile.Finalizer) {
+		return ctrl.Result{}, nil
+	}
 
+	params := reconcile.Params{
+		Client:   r.Client,
+		Instance: instance,
+		Log:      log,
+		Scheme:   r.scheme,
+		Recorder: r.recorder,
+	}
+
+	remaining, err := reconcile.CleanUp(ctx, params)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(remaining) > 0 {
+		log.V(1).Info("waiting for the cleanup of the instance", "remaining", remaining)
+		if err := reconcile.UpdateCleanupStatus(ctx, params, remaining); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: cleanupPollInterval}, nil
+	}
+
+	patch := client.MergeFromWithOptions(instance.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	controllerutil.RemoveFinalizer(&instance, reconcile.Finalizer)
+	if err := r.Patch(ctx, &instance, patch); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	r.backoff(types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}, nil)
+	log.Info("instance cleaned up")
 	return ctrl.Result{}, nil
 }
 
-// RunTasks runs all the tasks associated with this reconciler.
-func (r *SplunkOtelAgentReconciler) RunTasks(ctx context.Context, params reconcile.Params) error {
+// RunTasks runs all the tasks associated with this reconciler, returning the ones that failed. It stops at the first
+// failing task that bails on error, returning its error as well.
+func (r *SplunkOtelAgentReconciler) RunTasks(ctx context.Context, params reconcile.Params) ([]reconcile.TaskFailure, error) {
+	var failures []reconcile.TaskFailure
 	for _, task := range r.tasks {
 		if err := task.Do(ctx, params); err != nil {
 			r.logger.Error(err, fmt.Sprintf("failed to reconcile %s", task.Name))
+			failures = append(failures, reconcile.TaskFailure{Task: task.Name, Err: err})
 			if task.BailOnError {
-				return err
+				return failures, err
 			}
 		}
 	}
 
-	return nil
+	return failures, nil
+}
+
+// backoff records the outcome of the tasks for the instance and returns the delay before it should be reconciled
+// again, zero when no task failed. Tasks that succeeded start over from the minimum delay on their next failure.
+func (r *SplunkOtelAgentReconciler) backoff(nsn types.NamespacedName, failures []reconcile.TaskFailure) time.Duration {
+	r.failuresMu.Lock()
+	defer r.failuresMu.Unlock()
+
+	if len(failures) == 0 {
+		delete(r.failures, nsn)
+		return 0
+	}
+
+	counts := map[string]int{}
+	consecutive := 0
+	for _, failure := range failures {
+		counts[failure.Task] = r.failures[nsn][failure.Task] + 1
+		if counts[failure.Task] > consecutive {
+			consecutive = counts[failure.Task]
+		}
+	}
+	r.failures[nsn] = counts
+
+	delay := minTaskBackoff
+	for i := 1; i < consecutive && delay < maxTaskBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxTaskBackoff {
+		delay = maxTaskBackoff
+	}
+	return delay
+}
+
+func (r *SplunkOtelAgentReconciler) event(object runtime.Object, eventType, reason, message string) {
+	if r.recorder != nil {
+		r.recorder.Event(object, eventType, reason, message)
+	}
 }
 
+// Field indexes of the agents by the Secrets and ConfigMaps they reference.
+const (
+	referencedSecretsIndex    = "spec.referencedSecrets"
+	referencedConfigMapsIndex = "spec.referencedConfigMaps"
+)
+
 // SetupWithManager sets up the controller with the Manager.
+// The Secrets and ConfigMaps are only watched for their metadata, and only their events matching an agent of their
+// namespace are queued: the operator doesn't keep a copy of every Secret and ConfigMap of the cluster in memory.
 func (r *SplunkOtelAgentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	ctx := context.Background()
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &otelv1alpha1.Agent{}, referencedSecretsIndex, referencedSecrets); err != nil {
+		return fmt.Errorf("failed to index the agents by secret: %w", err)
+	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &otelv1alpha1.Agent{}, referencedConfigMapsIndex, referencedConfigMaps); err != nil {
+		return fmt.Errorf("failed to index the agents by config map: %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&otelv1alpha1.Agent{}).
-		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.ConfigMap{}, builder.OnlyMetadata).
 		Owns(&corev1.ServiceAccount{}).
+		Owns(&rbacv1.Role{}).
+		Owns(&rbacv1.RoleBinding{}).
 		Owns(&corev1.Service{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&appsv1.DaemonSet{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.referencingAgents(referencedSecretsIndex)), builder.OnlyMetadata).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.referencingAgents(referencedConfigMapsIndex)), builder.OnlyMetadata).
 		Complete(r)
 }
+
+// referencingAgents maps a Secret or ConfigMap to the agents of its namespace referencing it through the given
+// index, so that their pods are rolled out when its data changes and their access token is synced from it.
+func (r *SplunkOtelAgentReconciler) referencingAgents(index string) handler.MapFunc {
+	return func(obj client.Object) []ctrl.Request {
+		list := &v1alpha1.AgentList{}
+		if err := r.List(context.Background(), list, client.InNamespace(obj.GetNamespace()), client.MatchingFields{index: obj.GetName()}); err != nil {
+			r.logger.Error(err, "failed to list the agents referencing an object", "name", obj.GetName(), "namespace", obj.GetNamespace())
+			return nil
+		}
+
+		requests := make([]ctrl.Request, 0, len(list.Items))
+		for i := range list.Items {
+			requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+		}
+		return requests
+	}
+}
+
+// referencedSecrets returns the names of the Secrets an agent references, including the source of its access token.
+func referencedSecrets(obj client.Object) []string {
+	agent, ok := obj.(*v1alpha1.Agent)
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	if source, _, ok := collector.AccessTokenSource(*agent); ok {
+		names = append(names, source.Name)
+	}
+	for _, component := range v1alpha1.Components {
+		secrets, _ := collector.ReferencedObjects(*agent.CollectorSpec(component))
+		for _, name := range secrets {
+			if !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// referencedConfigMaps returns the names of the ConfigMaps an agent references.
+func referencedConfigMaps(obj client.Object) []string {
+	agent, ok := obj.(*v1alpha1.Agent)
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	for _, component := range v1alpha1.Components {
+		_, configMaps := collector.ReferencedObjects(*agent.CollectorSpec(component))
+		for _, name := range configMaps {
+			if !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
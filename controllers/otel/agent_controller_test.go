@@ -25,6 +25,9 @@ import (
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/kubectl/pkg/scheme"
@@ -109,7 +112,18 @@ func TestNewObjectsOnReconciliation(t *testing.T) {
 
 	// cleanup
 	require.NoError(t, k8sClient.Delete(context.Background(), created))
+	_, err = reconciler.Reconcile(context.Background(), req)
+	require.NoError(t, err)
 
+	// the cluster-scoped objects are deleted before the instance
+	{
+		list := &rbacv1.ClusterRoleList{}
+		err = k8sClient.List(context.Background(), list, opts[1])
+		assert.NoError(t, err)
+		assert.Empty(t, list.Items)
+	}
+	err = k8sClient.Get(context.Background(), nsn, &v1alpha1.Agent{})
+	assert.True(t, apierrors.IsNotFound(err))
 }
 
 func TestContinueOnRecoverableFailure(t *testing.T) {
@@ -134,11 +148,38 @@ func TestContinueOnRecoverableFailure(t *testing.T) {
 	}
 
 	// test
-	err := reconciler.RunTasks(context.Background(), reconcile.Params{})
+	failures, err := reconciler.RunTasks(context.Background(), reconcile.Params{})
 
 	// verify
 	assert.NoError(t, err)
 	assert.True(t, taskCalled)
+	require.Len(t, failures, 1)
+	assert.Equal(t, "should-fail", failures[0].Task)
+}
+
+func TestBackoffOnTaskFailures(t *testing.T) {
+	// prepare
+	reconciler := NewReconciler(logger, nil, nil, nil)
+	nsn := types.NamespacedName{Name: "my-instance", Namespace: "default"}
+	failure := reconcile.TaskFailure{Task: "services", Err: errors.New("should fail")}
+
+	// test and verify
+	assert.Equal(t, minTaskBackoff, reconciler.backoff(nsn, []reconcile.TaskFailure{failure}))
+	assert.Equal(t, 2*minTaskBackoff, reconciler.backoff(nsn, []reconcile.TaskFailure{failure}))
+	assert.Equal(t, 4*minTaskBackoff, reconciler.backoff(nsn, []reconcile.TaskFailure{failure}))
+
+	// another task failing for the first time doesn't reset the delay of the services
+	other := reconcile.TaskFailure{Task: "agent", Err: errors.New("should fail")}
+	assert.Equal(t, 8*minTaskBackoff, reconciler.backoff(nsn, []reconcile.TaskFailure{failure, other}))
+
+	// a successful reconciliation starts over
+	assert.Zero(t, reconciler.backoff(nsn, nil))
+	assert.Equal(t, minTaskBackoff, reconciler.backoff(nsn, []reconcile.TaskFailure{other}))
+
+	for i := 0; i < 20; i++ {
+		reconciler.backoff(nsn, []reconcile.TaskFailure{other})
+	}
+	assert.Equal(t, maxTaskBackoff, reconciler.backoff(nsn, []reconcile.TaskFailure{other}))
 }
 
 func TestBreakOnUnrecoverableError(t *testing.T) {
@@ -186,6 +227,8 @@ func TestBreakOnUnrecoverableError(t *testing.T) {
 
 	// cleanup
 	assert.NoError(t, k8sClient.Delete(context.Background(), created))
+	_, err = reconciler.Reconcile(context.Background(), req)
+	assert.NoError(t, err)
 }
 
 func TestSkipWhenInstanceDoesNotExist(t *testing.T) {
@@ -211,3 +254,76 @@ func TestSkipWhenInstanceDoesNotExist(t *testing.T) {
 	// verify
 	assert.NoError(t, err)
 }
+
+func TestSkipTasksWhenPaused(t *testing.T) {
+	// prepare
+	nsn := types.NamespacedName{Name: "paused-instance", Namespace: "default"}
+	reconciler := NewReconciler(logger, k8sClient, testScheme, nil)
+	reconciler.tasks = []Task{
+		{
+			Name: "should-not-be-called",
+			Do: func(context.Context, reconcile.Params) error {
+				assert.Fail(t, "should not have been called")
+				return nil
+			},
+		},
+	}
+
+	created := &v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        nsn.Name,
+			Namespace:   nsn.Namespace,
+			Annotations: map[string]string{v1alpha1.AnnotationReconcile: v1alpha1.ReconcilePaused},
+		},
+	}
+	err := k8sClient.Create(context.Background(), created)
+	require.NoError(t, err)
+
+	// test
+	req := k8sreconcile.Request{
+		NamespacedName: nsn,
+	}
+	_, err = reconciler.Reconcile(context.Background(), req)
+
+	// verify
+	require.NoError(t, err)
+	actual := &v1alpha1.Agent{}
+	require.NoError(t, k8sClient.Get(context.Background(), nsn, actual))
+	assert.True(t, meta.IsStatusConditionTrue(actual.Status.Conditions, v1alpha1.ConditionPaused))
+
+	// cleanup
+	assert.NoError(t, k8sClient.Delete(context.Background(), created))
+	_, err = reconciler.Reconcile(context.Background(), req)
+	assert.NoError(t, err)
+}
+
+func TestReferencedObjectsIndexes(t *testing.T) {
+	// prepare
+	secretEnv := func(name string) corev1.EnvVar {
+		return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: "key",
+		}}}
+	}
+	configMapEnv := func(name string) corev1.EnvVar {
+		return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: "key",
+		}}}
+	}
+	agent := &v1alpha1.Agent{
+		Spec: v1alpha1.AgentSpec{
+			Agent:           v1alpha1.CollectorSpec{Env: []corev1.EnvVar{secretEnv("shared"), configMapEnv("agent")}},
+			ClusterReceiver: v1alpha1.CollectorSpec{Env: []corev1.EnvVar{secretEnv("shared")}},
+			Gateway:         v1alpha1.CollectorSpec{Env: []corev1.EnvVar{secretEnv("gateway"), configMapEnv("agent")}},
+			AccessToken:     &v1alpha1.AccessTokenSource{SecretRef: v1alpha1.SecretKeyReference{Name: "token"}},
+		},
+	}
+
+	// test
+	secrets := referencedSecrets(agent)
+	configMaps := referencedConfigMaps(agent)
+
+	// verify
+	assert.ElementsMatch(t, []string{"token", "shared", "gateway"}, secrets)
+	assert.ElementsMatch(t, []string{"agent"}, configMaps)
+	assert.Empty(t, referencedSecrets(&corev1.Secret{}))
+}
@@ -0,0 +1,59 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+)
+
+// AccessTokenSource returns the secret and key the access token of the instance is synced from, if the operator
+// manages the access token of the instance.
+func AccessTokenSource(otelcol v1alpha1.Agent) (types.NamespacedName, string, bool) {
+	if otelcol.Spec.AccessToken == nil {
+		return types.NamespacedName{}, "", false
+	}
+
+	// the source is always looked up in the namespace of the instance, so that it can't be used to read others
+	ref := otelcol.Spec.AccessToken.SecretRef
+	source := types.NamespacedName{Namespace: otelcol.Namespace, Name: ref.Name}
+	key := ref.Key
+	if key == "" {
+		key = v1alpha1.AccessTokenSecretKey
+	}
+	return source, key, true
+}
+
+// AccessTokenSecret returns the secret the collectors of the instance read the given access token from.
+func AccessTokenSecret(otelcol v1alpha1.Agent, token []byte) corev1.Secret {
+	labels := Labels(otelcol)
+	labels["app.kubernetes.io/name"] = v1alpha1.AccessTokenSecret
+
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        v1alpha1.AccessTokenSecret,
+			Namespace:   otelcol.Namespace,
+			Labels:      labels,
+			Annotations: otelcol.Annotations,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			v1alpha1.AccessTokenSecretKey: token,
+		},
+	}
+}
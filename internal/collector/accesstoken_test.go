@@ -0,0 +1,56 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+)
+
+func TestAccessTokenSource(t *testing.T) {
+	otelcol := v1alpha1.Agent{}
+	otelcol.Namespace = "observability"
+
+	_, _, ok := AccessTokenSource(otelcol)
+	assert.False(t, ok, "the access token isn't managed unless referenced")
+
+	otelcol.Spec.AccessToken = &v1alpha1.AccessTokenSource{SecretRef: v1alpha1.SecretKeyReference{Name: "tokens"}}
+	source, key, ok := AccessTokenSource(otelcol)
+	assert.True(t, ok)
+	assert.Equal(t, types.NamespacedName{Namespace: "observability", Name: "tokens"}, source)
+	assert.Equal(t, "access-token", key)
+
+	otelcol.Spec.AccessToken.SecretRef = v1alpha1.SecretKeyReference{Name: "tokens", Key: "splunk"}
+	source, key, _ = AccessTokenSource(otelcol)
+	assert.Equal(t, types.NamespacedName{Namespace: "observability", Name: "tokens"}, source)
+	assert.Equal(t, "splunk", key)
+}
+
+func TestAccessTokenSecret(t *testing.T) {
+	otelcol := v1alpha1.Agent{}
+	otelcol.Name = "my-instance"
+	otelcol.Namespace = "observability"
+
+	secret := AccessTokenSecret(otelcol, []byte("token"))
+
+	assert.Equal(t, "splunk-access-token", secret.Name)
+	assert.Equal(t, "observability", secret.Namespace)
+	assert.Equal(t, "observability.my-instance", secret.Labels["app.kubernetes.io/instance"])
+	assert.Equal(t, []byte("token"), secret.Data["access-token"])
+}
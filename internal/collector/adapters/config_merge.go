@@ -0,0 +1,144 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapters
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigWithOverlay returns the given configuration with the overlay deep-merged onto it, see MergeConfig.
+// The configuration is returned as is when there's no overlay.
+func ConfigWithOverlay(configStr, overlayStr string) (string, error) {
+	if strings.TrimSpace(overlayStr) == "" {
+		return configStr, nil
+	}
+
+	config, err := ConfigFromString(configStr)
+	if err != nil {
+		return "", err
+	}
+
+	overlay, err := ConfigFromString(overlayStr)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse the config overlay: %w", err)
+	}
+
+	merged, err := MergeConfig(config, overlay)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := yaml.Marshal(merged)
+	if err != nil {
+		return "", fmt.Errorf("couldn't marshal the merged configuration: %w", err)
+	}
+	return string(res), nil
+}
+
+// MergeConfig deep-merges the overlay onto the base configuration, returning a new configuration map.
+// Maps are merged key by key and any other overlay value replaces the base one, except for the lists of components
+// in service.extensions and service.pipelines.*.{receivers,processors,exporters}: the overlay entries are appended
+// to the base entries they're not already part of, so that a component can be added without repeating the others.
+func MergeConfig(base, overlay map[interface{}]interface{}) (map[interface{}]interface{}, error) {
+	return mergeMaps(base, overlay, nil)
+}
+
+func mergeMaps(base, overlay map[interface{}]interface{}, path []string) (map[interface{}]interface{}, error) {
+	res := make(map[interface{}]interface{}, len(base)+len(overlay))
+	for k, v := range base {
+		res[k] = v
+	}
+
+	for k, overlayValue := range overlay {
+		keyPath := append(append([]string{}, path...), fmt.Sprintf("%v", k))
+
+		baseValue, exists := res[k]
+		if !exists || baseValue == nil {
+			res[k] = overlayValue
+			continue
+		}
+
+		if isComponentList(keyPath) {
+			merged, err := mergeComponentLists(baseValue, overlayValue, keyPath)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = merged
+			continue
+		}
+
+		baseMap, baseIsMap := baseValue.(map[interface{}]interface{})
+		overlayMap, overlayIsMap := overlayValue.(map[interface{}]interface{})
+		if baseIsMap && overlayIsMap {
+			merged, err := mergeMaps(baseMap, overlayMap, keyPath)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = merged
+			continue
+		}
+
+		res[k] = overlayValue
+	}
+
+	return res, nil
+}
+
+// isComponentList reports whether the given path points to a list of component names in the service section.
+func isComponentList(path []string) bool {
+	switch {
+	case len(path) == 2 && path[0] == "service" && path[1] == "extensions":
+		return true
+	case len(path) == 4 && path[0] == "service" && path[1] == "pipelines":
+		return path[3] == "receivers" || path[3] == "processors" || path[3] == "exporters"
+	}
+	return false
+}
+
+func mergeComponentLists(base, overlay interface{}, path []string) ([]interface{}, error) {
+	baseList, ok := base.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("couldn't merge %q, the base config value isn't a list", strings.Join(path, "."))
+	}
+
+	if overlay == nil {
+		return baseList, nil
+	}
+	overlayList, ok := overlay.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("couldn't merge %q, the overlay value isn't a list", strings.Join(path, "."))
+	}
+
+	res := append([]interface{}{}, baseList...)
+	for _, entry := range overlayList {
+		if !containsEntry(res, entry) {
+			res = append(res, entry)
+		}
+	}
+	return res, nil
+}
+
+func containsEntry(list []interface{}, entry interface{}) bool {
+	for _, e := range list {
+		if reflect.DeepEqual(e, entry) {
+			return true
+		}
+	}
+	return false
+}
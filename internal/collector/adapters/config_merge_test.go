@@ -0,0 +1,160 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapters_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/adapters"
+)
+
+func TestMergeConfig(t *testing.T) {
+	// prepare
+	base, err := adapters.ConfigFromString(`receivers:
+  otlp:
+    protocols:
+      grpc:
+      http:
+exporters:
+  signalfx:
+    realm: us0
+    timeout: 5s
+extensions:
+  health_check:
+service:
+  extensions: [health_check]
+  pipelines:
+    metrics:
+      receivers: [otlp]
+      exporters: [signalfx]
+`)
+	require.NoError(t, err)
+
+	overlay, err := adapters.ConfigFromString(`receivers:
+  prometheus/app:
+    config:
+      scrape_configs: []
+exporters:
+  signalfx:
+    timeout: 10s
+extensions:
+  zpages:
+service:
+  extensions: [zpages]
+  pipelines:
+    metrics:
+      receivers: [otlp, prometheus/app]
+`)
+	require.NoError(t, err)
+
+	// test
+	merged, err := adapters.MergeConfig(base, overlay)
+	require.NoError(t, err)
+
+	// verify
+	res, err := yaml.Marshal(merged)
+	require.NoError(t, err)
+	assert.Equal(t, `exporters:
+  signalfx:
+    realm: us0
+    timeout: 10s
+extensions:
+  health_check: null
+  zpages: null
+receivers:
+  otlp:
+    protocols:
+      grpc: null
+      http: null
+  prometheus/app:
+    config:
+      scrape_configs: []
+service:
+  extensions:
+  - health_check
+  - zpages
+  pipelines:
+    metrics:
+      exporters:
+      - signalfx
+      receivers:
+      - otlp
+      - prometheus/app
+`, string(res))
+
+	// the base config shouldn't be changed
+	assert.Len(t, base["receivers"], 1)
+}
+
+func TestMergeConfigReplacesOtherLists(t *testing.T) {
+	// prepare
+	base := map[interface{}]interface{}{
+		"processors": map[interface{}]interface{}{
+			"resourcedetection": map[interface{}]interface{}{"detectors": []interface{}{"env", "system"}},
+		},
+	}
+	overlay := map[interface{}]interface{}{
+		"processors": map[interface{}]interface{}{
+			"resourcedetection": map[interface{}]interface{}{"detectors": []interface{}{"gcp"}},
+		},
+	}
+
+	// test
+	merged, err := adapters.MergeConfig(base, overlay)
+
+	// verify
+	require.NoError(t, err)
+	assert.Equal(t, map[interface{}]interface{}{
+		"processors": map[interface{}]interface{}{
+			"resourcedetection": map[interface{}]interface{}{"detectors": []interface{}{"gcp"}},
+		},
+	}, merged)
+}
+
+func TestMergeConfigInvalidComponentList(t *testing.T) {
+	// prepare
+	base, err := adapters.ConfigFromString(`service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+`)
+	require.NoError(t, err)
+	overlay, err := adapters.ConfigFromString(`service:
+  pipelines:
+    traces:
+      receivers: jaeger
+`)
+	require.NoError(t, err)
+
+	// test
+	merged, err := adapters.MergeConfig(base, overlay)
+
+	// verify
+	assert.Nil(t, merged)
+	assert.EqualError(t, err, `couldn't merge "service.pipelines.traces.receivers", the overlay value isn't a list`)
+}
+
+func TestConfigWithoutOverlay(t *testing.T) {
+	// test
+	res, err := adapters.ConfigWithOverlay("receivers:\n  otlp:\n", "")
+
+	// verify
+	assert.NoError(t, err)
+	assert.Equal(t, "receivers:\n  otlp:\n", res, "the config should be kept as is")
+}
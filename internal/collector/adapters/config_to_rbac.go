@@ -0,0 +1,89 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/rbac"
+)
+
+// ConfigToRBACRules converts the incoming configuration object into the rules the collector needs to talk to the
+// Kubernetes API, like read access to the nodes for the kubeletstats receiver. Every component defined in the
+// configuration is taken into account, whether the service uses it or not.
+func ConfigToRBACRules(logger logr.Logger, config map[interface{}]interface{}) []rbacv1.PolicyRule {
+	return configRules(logger, config, rbac.For)
+}
+
+// ConfigToNamespacedRBACRules converts the incoming configuration object into the rules the collector needs in its
+// own namespace only, like the config maps some receivers elect a leader with.
+func ConfigToNamespacedRBACRules(logger logr.Logger, config map[interface{}]interface{}) []rbacv1.PolicyRule {
+	return configRules(logger, config, rbac.NamespacedFor)
+}
+
+// ValidateRBAC checks that the collector can be granted what its configuration asks for: the objects a k8sobjects
+// receiver reads have to be allowed. All the denied objects are reported in the returned error.
+func ValidateRBAC(config map[interface{}]interface{}) error {
+	var problems []string
+	forEachComponent(config, func(section, name string, component map[interface{}]interface{}) {
+		if denied := rbac.DeniedObjects(section, name, component); len(denied) > 0 {
+			problems = append(problems, fmt.Sprintf("the %q receiver can't be granted access to %s", name, strings.Join(denied, ", ")))
+		}
+	})
+	if len(problems) == 0 {
+		return nil
+	}
+
+	// components are visited in no particular order
+	sort.Strings(problems)
+	return errors.New(strings.Join(problems, "; "))
+}
+
+func configRules(logger logr.Logger, config map[interface{}]interface{}, lookup func(logr.Logger, string, string, map[interface{}]interface{}) []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	var rules []rbacv1.PolicyRule
+	forEachComponent(config, func(section, name string, component map[interface{}]interface{}) {
+		rules = append(rules, lookup(logger, section, name, component)...)
+	})
+	return rbac.Merge(rules)
+}
+
+// forEachComponent calls fn for every receiver, processor and extension defined in the configuration.
+func forEachComponent(config map[interface{}]interface{}, fn func(section, name string, component map[interface{}]interface{})) {
+	for _, section := range []string{"receivers", "processors", "extensions"} {
+		components, ok := config[section].(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+
+		for key, val := range components {
+			name, ok := key.(string)
+			if !ok {
+				continue
+			}
+			component, ok := val.(map[interface{}]interface{})
+			if !ok {
+				// components declared without settings, like "k8sattributes: null", use their defaults
+				component = map[interface{}]interface{}{}
+			}
+			fn(section, name, component)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapters_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/adapters"
+)
+
+func TestExtractRBACRulesFromConfig(t *testing.T) {
+	configStr := `extensions:
+  k8s_observer:
+    observe_nodes: true
+receivers:
+  otlp:
+  kubeletstats:
+    auth_type: serviceAccount
+processors:
+  k8sattributes:
+  batch:
+`
+	// prepare
+	config, err := adapters.ConfigFromString(configStr)
+	require.NoError(t, err)
+
+	// test
+	rules := adapters.ConfigToRBACRules(logger, config)
+
+	// verify
+	readOnly := []string{"get", "list", "watch"}
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"nodes/proxy", "nodes/stats"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"namespaces", "nodes", "pods"}, Verbs: readOnly},
+		{APIGroups: []string{"apps"}, Resources: []string{"replicasets"}, Verbs: readOnly},
+	}, rules)
+}
+
+func TestNoRBACRulesForPlainConfig(t *testing.T) {
+	config, err := adapters.ConfigFromString("receivers:\n  otlp:\n")
+	require.NoError(t, err)
+
+	assert.Empty(t, adapters.ConfigToRBACRules(logger, config))
+}
+
+func TestExtractNamespacedRBACRulesFromConfig(t *testing.T) {
+	// prepare
+	config, err := adapters.ConfigFromString(`receivers:
+  smartagent/cluster:
+    type: kubernetes-cluster
+  kubeletstats:
+`)
+	require.NoError(t, err)
+
+	// test
+	rules := adapters.ConfigToNamespacedRBACRules(logger, config)
+
+	// verify
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"create", "get", "update"}},
+	}, rules)
+	assert.NotContains(t, adapters.ConfigToRBACRules(logger, config), rules[0])
+}
+
+func TestValidateRBAC(t *testing.T) {
+	// prepare
+	allowed, err := adapters.ConfigFromString(`receivers:
+  k8sobjects:
+    objects:
+      - name: pods
+      - name: events
+        group: events.k8s.io
+`)
+	require.NoError(t, err)
+	denied, err := adapters.ConfigFromString(`receivers:
+  k8sobjects/secrets:
+    objects:
+      - name: secrets
+      - name: pods
+  k8sobjects/crds:
+    objects:
+      - name: customresourcedefinitions
+        group: apiextensions.k8s.io
+`)
+	require.NoError(t, err)
+
+	// test and verify
+	assert.NoError(t, adapters.ValidateRBAC(allowed))
+	assert.EqualError(t, adapters.ValidateRBAC(denied), `the "k8sobjects/crds" receiver can't be granted access to customresourcedefinitions.apiextensions.k8s.io; the "k8sobjects/secrets" receiver can't be granted access to secrets`)
+}
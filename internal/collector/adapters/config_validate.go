@@ -0,0 +1,126 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrNoPipelines indicates that the service of the configuration doesn't define any pipeline.
+	ErrNoPipelines = errors.New("no pipelines defined in the service section of the configuration")
+)
+
+// pipelineComponents are the lists of components a pipeline references, with the sections they can be defined in.
+var pipelineComponents = []struct {
+	list     string
+	kind     string
+	sections []string
+	required bool
+}{
+	{list: "receivers", kind: "receiver", sections: []string{"receivers", "connectors"}, required: true},
+	{list: "processors", kind: "processor", sections: []string{"processors"}},
+	{list: "exporters", kind: "exporter", sections: []string{"exporters", "connectors"}, required: true},
+}
+
+// ValidateConfig checks that the configuration would be accepted by the collector: every pipeline needs at least one
+// receiver and one exporter, and the components referenced by the service have to be defined in their section.
+// Connectors may be referenced both as receivers and exporters. All problems found are reported in the returned error.
+func ValidateConfig(config map[interface{}]interface{}) error {
+	service, ok := config["service"].(map[interface{}]interface{})
+	if !ok {
+		return ErrNoPipelines
+	}
+
+	var problems []string
+	if extensions, ok := service["extensions"]; ok && extensions != nil {
+		problems = append(problems, checkReferences(config, "service.extensions", extensions, "extension", "extensions")...)
+	}
+
+	pipelines, ok := service["pipelines"].(map[interface{}]interface{})
+	if !ok || len(pipelines) == 0 {
+		problems = append(problems, ErrNoPipelines.Error())
+		return errors.New(strings.Join(problems, "; "))
+	}
+
+	names := make([]string, 0, len(pipelines))
+	for k := range pipelines {
+		names = append(names, fmt.Sprintf("%v", k))
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		pipeline, ok := pipelines[name].(map[interface{}]interface{})
+		if !ok {
+			problems = append(problems, fmt.Sprintf("pipeline %q has no receivers and no exporters", name))
+			continue
+		}
+
+		for _, c := range pipelineComponents {
+			refs := pipeline[c.list]
+			if list, isList := refs.([]interface{}); refs == nil || isList && len(list) == 0 {
+				if c.required {
+					problems = append(problems, fmt.Sprintf("pipeline %q has no %s", name, c.list))
+				}
+				continue
+			}
+
+			path := fmt.Sprintf("service.pipelines.%s.%s", name, c.list)
+			problems = append(problems, checkReferences(config, path, refs, c.kind, c.sections...)...)
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkReferences returns a problem for each component in refs that isn't defined in any of the given sections.
+func checkReferences(config map[interface{}]interface{}, path string, refs interface{}, kind string, sections ...string) []string {
+	list, ok := refs.([]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("%q should be a list of component names", path)}
+	}
+
+	var problems []string
+	for _, ref := range list {
+		name, ok := ref.(string)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%q should be a list of component names", path))
+			continue
+		}
+		if !isDefined(config, name, sections) {
+			problems = append(problems, fmt.Sprintf("%q references the undefined %s %q", path, kind, name))
+		}
+	}
+	return problems
+}
+
+func isDefined(config map[interface{}]interface{}, name string, sections []string) bool {
+	for _, section := range sections {
+		components, ok := config[section].(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := components[name]; ok {
+			return true
+		}
+	}
+	return false
+}
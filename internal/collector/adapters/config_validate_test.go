@@ -0,0 +1,127 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapters_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/adapters"
+)
+
+func TestValidateConfig(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		config   string
+		expected string
+	}{
+		{
+			desc: "valid",
+			config: `receivers:
+  otlp:
+processors:
+  batch:
+exporters:
+  signalfx:
+connectors:
+  forward:
+extensions:
+  health_check:
+service:
+  extensions: [health_check]
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [batch]
+      exporters: [forward]
+    traces/2:
+      receivers: [forward]
+      exporters: [signalfx]
+`,
+		},
+		{
+			desc:     "no service",
+			config:   "receivers:\n  otlp:\n",
+			expected: "no pipelines defined in the service section of the configuration",
+		},
+		{
+			desc: "undefined components",
+			config: `receivers:
+  otlp:
+exporters:
+  signalfx:
+service:
+  extensions: [health_check]
+  pipelines:
+    metrics:
+      receivers: [otlp, prometheus]
+      processors: [batch]
+      exporters: [signalfx]
+`,
+			expected: `"service.extensions" references the undefined extension "health_check"; ` +
+				`"service.pipelines.metrics.receivers" references the undefined receiver "prometheus"; ` +
+				`"service.pipelines.metrics.processors" references the undefined processor "batch"`,
+		},
+		{
+			desc: "pipelines without receivers or exporters",
+			config: `receivers:
+  otlp:
+exporters:
+  signalfx:
+service:
+  pipelines:
+    logs:
+      receivers: [otlp]
+    metrics:
+      receivers: []
+      exporters: [signalfx]
+    traces:
+`,
+			expected: `pipeline "logs" has no exporters; pipeline "metrics" has no receivers; pipeline "traces" has no receivers and no exporters`,
+		},
+		{
+			desc: "references not in a list",
+			config: `receivers:
+  otlp:
+exporters:
+  signalfx:
+service:
+  pipelines:
+    metrics:
+      receivers: otlp
+      exporters: [signalfx]
+`,
+			expected: `"service.pipelines.metrics.receivers" should be a list of component names`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			// prepare
+			config, err := adapters.ConfigFromString(tt.config)
+			require.NoError(t, err)
+
+			// test
+			err = adapters.ValidateConfig(config)
+
+			// verify
+			if tt.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapters
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// WarningOptions describe how the collector running a configuration is deployed.
+type WarningOptions struct {
+	// CollectorVersion is the version of the collector running the configuration, deprecations are reported from the
+	// version they were introduced in.
+	CollectorVersion string
+
+	// HostNetwork is set when the collector runs in the host networking namespace.
+	HostNetwork bool
+}
+
+// warningRule inspects a parsed configuration, returning a warning for each risky setting it finds.
+type warningRule func(config map[interface{}]interface{}, opts WarningOptions) []string
+
+var warningRules = []warningRule{
+	debugExportersRule,
+	memoryLimiterRule,
+	deprecatedComponentsRule,
+	hostPortsRule,
+}
+
+// ConfigWarnings returns the warnings of all the rules for the given configuration. Unlike ValidateConfig, warnings
+// are about configurations the collector accepts but that are likely to cause trouble once deployed.
+func ConfigWarnings(config map[interface{}]interface{}, opts WarningOptions) []string {
+	var warnings []string
+	for _, rule := range warningRules {
+		warnings = append(warnings, rule(config, opts)...)
+	}
+	return warnings
+}
+
+// deprecation describes a component, or a setting of a component, deprecated from a given collector version.
+type deprecation struct {
+	section       string
+	componentType string
+	setting       string
+	since         string
+	replacement   string
+}
+
+var deprecations = []deprecation{
+	{section: "receivers", componentType: "influxdb", setting: "metrics_schema", since: "0.31.0"},
+	{section: "exporters", componentType: "logging", setting: "loglevel", since: "0.72.0", replacement: "the 'verbosity' setting"},
+	{section: "processors", componentType: "resourcedetection", setting: "attributes", since: "0.81.0", replacement: "the 'resource_attributes' setting of each detector"},
+	{section: "exporters", componentType: "logging", since: "0.86.0", replacement: "the debug exporter"},
+	{section: "extensions", componentType: "memory_ballast", since: "0.97.0", replacement: "the memory_limiter processor"},
+	{section: "exporters", componentType: "sapm", since: "0.116.0", replacement: "the otlphttp exporter"},
+}
+
+// debugExportersRule warns about exporters writing all the telemetry to the collector logs in active pipelines.
+func debugExportersRule(config map[interface{}]interface{}, _ WarningOptions) []string {
+	var warnings []string
+	forEachPipeline(config, func(name string, pipeline map[interface{}]interface{}) {
+		for _, exporter := range componentNames(pipeline["exporters"]) {
+			if isOfType(exporter, "logging") || isOfType(exporter, "debug") {
+				warnings = append(warnings, fmt.Sprintf("pipeline %q exports to %q, which writes all the telemetry to the collector logs and is meant for troubleshooting", name, exporter))
+			}
+		}
+	})
+	return warnings
+}
+
+// memoryLimiterRule warns about pipelines without memory_limiter, or running it after the batch processor, which makes
+// the collector buffer data it should have refused.
+func memoryLimiterRule(config map[interface{}]interface{}, _ WarningOptions) []string {
+	var warnings []string
+	forEachPipeline(config, func(name string, pipeline map[interface{}]interface{}) {
+		processors := componentNames(pipeline["processors"])
+		limiter, batch := -1, -1
+		for i, processor := range processors {
+			if isOfType(processor, "memory_limiter") && limiter < 0 {
+				limiter = i
+			}
+			if isOfType(processor, "batch") && batch < 0 {
+				batch = i
+			}
+		}
+
+		switch {
+		case limiter < 0:
+			warnings = append(warnings, fmt.Sprintf("pipeline %q has no memory_limiter processor, the collector may run out of memory under load", name))
+		case batch >= 0 && batch < limiter:
+			warnings = append(warnings, fmt.Sprintf("pipeline %q runs the %q processor before %q, the memory_limiter processor should come first", name, processors[batch], processors[limiter]))
+		}
+	})
+	return warnings
+}
+
+// deprecatedComponentsRule warns about components and settings deprecated in the version of the collector running the
+// configuration. The upgrade steps migrate them once the operator ships a newer collector.
+func deprecatedComponentsRule(config map[interface{}]interface{}, opts WarningOptions) []string {
+	collectorV, err := semver.NewVersion(opts.CollectorVersion)
+	if err != nil {
+		return nil
+	}
+
+	var warnings []string
+	for _, d := range deprecations {
+		if collectorV.LessThan(semver.MustParse(d.since)) {
+			continue
+		}
+
+		components, ok := config[d.section].(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, name := range sortedKeys(components) {
+			if !isOfType(name, d.componentType) {
+				continue
+			}
+
+			suffix := ""
+			if d.replacement != "" {
+				suffix = fmt.Sprintf(", use %s instead", d.replacement)
+			}
+
+			if d.setting == "" {
+				warnings = append(warnings, fmt.Sprintf("%s %q is deprecated since v%s%s", strings.TrimSuffix(d.section, "s"), name, d.since, suffix))
+				continue
+			}
+
+			settings, _ := components[name].(map[interface{}]interface{})
+			if _, ok := settings[d.setting]; ok {
+				warnings = append(warnings, fmt.Sprintf("the '%s' setting of %s %q is deprecated since v%s%s", d.setting, strings.TrimSuffix(d.section, "s"), name, d.since, suffix))
+			}
+		}
+	}
+	return warnings
+}
+
+// hostPortsRule warns about receivers listening on all the interfaces of the node on well-known ports, which are
+// likely to clash with services of the node when the collector runs in the host network.
+func hostPortsRule(config map[interface{}]interface{}, opts WarningOptions) []string {
+	if !opts.HostNetwork {
+		return nil
+	}
+
+	receivers, ok := config["receivers"].(map[interface{}]interface{})
+	if !ok {
+		return nil
+	}
+
+	var warnings []string
+	for _, name := range sortedKeys(receivers) {
+		for _, endpoint := range endpoints(receivers[name]) {
+			host, portStr, err := net.SplitHostPort(endpoint)
+			if err != nil || (host != "0.0.0.0" && host != "" && host != "::") {
+				continue
+			}
+			if port, err := strconv.Atoi(portStr); err == nil && port < 1024 {
+				warnings = append(warnings, fmt.Sprintf("receiver %q listens on %s on the host network, well-known ports may clash with services of the node", name, endpoint))
+			}
+		}
+	}
+	return warnings
+}
+
+func forEachPipeline(config map[interface{}]interface{}, fn func(name string, pipeline map[interface{}]interface{})) {
+	service, ok := config["service"].(map[interface{}]interface{})
+	if !ok {
+		return
+	}
+
+	pipelines, ok := service["pipelines"].(map[interface{}]interface{})
+	if !ok {
+		return
+	}
+
+	for _, name := range sortedKeys(pipelines) {
+		if pipeline, ok := pipelines[name].(map[interface{}]interface{}); ok {
+			fn(name, pipeline)
+		}
+	}
+}
+
+// endpoints returns the values of all the 'endpoint' settings found in the given component settings.
+func endpoints(settings interface{}) []string {
+	var res []string
+	switch s := settings.(type) {
+	case map[interface{}]interface{}:
+		for _, k := range sortedKeys(s) {
+			if endpoint, ok := s[k].(string); ok && k == "endpoint" {
+				res = append(res, endpoint)
+				continue
+			}
+			res = append(res, endpoints(s[k])...)
+		}
+	case []interface{}:
+		for _, v := range s {
+			res = append(res, endpoints(v)...)
+		}
+	}
+	return res
+}
+
+func componentNames(refs interface{}) []string {
+	list, _ := refs.([]interface{})
+	names := make([]string, 0, len(list))
+	for _, ref := range list {
+		if name, ok := ref.(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func isOfType(name, componentType string) bool {
+	return name == componentType || strings.HasPrefix(name, componentType+"/")
+}
+
+func sortedKeys(m map[interface{}]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if key, ok := k.(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,133 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapters_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/adapters"
+)
+
+func TestConfigWarnings(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		config   string
+		opts     adapters.WarningOptions
+		expected []string
+	}{
+		{
+			desc: "no warnings",
+			config: `receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:4317
+processors:
+  memory_limiter:
+  batch:
+exporters:
+  otlp:
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter, batch]
+      exporters: [otlp]
+`,
+			opts: adapters.WarningOptions{CollectorVersion: "0.71.0", HostNetwork: true},
+		},
+		{
+			desc: "debug exporters in pipelines",
+			config: `service:
+  pipelines:
+    logs:
+      processors: [memory_limiter]
+      exporters: [logging/debug, otlp]
+    traces:
+      processors: [memory_limiter]
+      exporters: [debug]
+`,
+			expected: []string{
+				`pipeline "logs" exports to "logging/debug", which writes all the telemetry to the collector logs and is meant for troubleshooting`,
+				`pipeline "traces" exports to "debug", which writes all the telemetry to the collector logs and is meant for troubleshooting`,
+			},
+		},
+		{
+			desc: "memory limiter",
+			config: `service:
+  pipelines:
+    logs:
+      processors: [batch]
+    metrics:
+      processors: [batch/1, memory_limiter/1, batch]
+`,
+			expected: []string{
+				`pipeline "logs" has no memory_limiter processor, the collector may run out of memory under load`,
+				`pipeline "metrics" runs the "batch/1" processor before "memory_limiter/1", the memory_limiter processor should come first`,
+			},
+		},
+		{
+			desc: "deprecated components",
+			config: `exporters:
+  logging:
+    loglevel: debug
+  sapm:
+extensions:
+  memory_ballast:
+`,
+			opts: adapters.WarningOptions{CollectorVersion: "0.97.0"},
+			expected: []string{
+				`the 'loglevel' setting of exporter "logging" is deprecated since v0.72.0, use the 'verbosity' setting instead`,
+				`exporter "logging" is deprecated since v0.86.0, use the debug exporter instead`,
+				`extension "memory_ballast" is deprecated since v0.97.0, use the memory_limiter processor instead`,
+			},
+		},
+		{
+			desc: "host ports",
+			config: `receivers:
+  otlp:
+    protocols:
+      http:
+        endpoint: 0.0.0.0:80
+  zipkin:
+    endpoint: 127.0.0.1:443
+  jaeger:
+    protocols:
+      thrift_http:
+        endpoint: :443
+`,
+			opts: adapters.WarningOptions{HostNetwork: true},
+			expected: []string{
+				`receiver "jaeger" listens on :443 on the host network, well-known ports may clash with services of the node`,
+				`receiver "otlp" listens on 0.0.0.0:80 on the host network, well-known ports may clash with services of the node`,
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			// prepare
+			config, err := adapters.ConfigFromString(tt.config)
+			require.NoError(t, err)
+
+			// test
+			warnings := adapters.ConfigWarnings(config, tt.opts)
+
+			// verify
+			assert.Equal(t, tt.expected, warnings)
+		})
+	}
+}
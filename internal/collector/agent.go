@@ -46,10 +46,10 @@ func Agent(logger logr.Logger, otelcol v1alpha1.Agent) appsv1.DaemonSet {
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels:      labels,
-					Annotations: otelcol.Annotations,
+					Annotations: PodAnnotations(otelcol, "agent"),
 				},
 				Spec: corev1.PodSpec{
-					ServiceAccountName: ServiceAccountName(otelcol),
+					ServiceAccountName: ServiceAccountName(otelcol, "agent"),
 					Containers:         []corev1.Container{Container(logger, otelcol.Spec.Agent)},
 					Volumes:            Volumes(otelcol.Spec.Agent, naming.ConfigMap(otelcol, "agent")),
 					Tolerations:        otelcol.Spec.Agent.Tolerations,
@@ -49,8 +49,10 @@ func TestDaemonSetNewDefault(t *testing.T) {
 
 	assert.Len(t, d.Spec.Template.Spec.Containers, 1)
 
-	// none of the default annotations should propagate down to the pod
-	assert.Empty(t, d.Spec.Template.Annotations)
+	// none of the default annotations should propagate down to the pod, only the config hash
+	assert.Len(t, d.Spec.Template.Annotations, 1)
+	assert.Contains(t, d.Spec.Template.Annotations, ConfigHashAnnotation)
+	assert.NotContains(t, d.Annotations, ConfigHashAnnotation)
 
 	// the pod selector should match the pod spec's labels
 	assert.Equal(t, d.Spec.Selector.MatchLabels, d.Spec.Template.Labels)
@@ -18,11 +18,21 @@ package collector
 import (
 	"crypto/sha256"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
 )
 
-// Annotations return the annotations for SplunkOtelAgent pod.
+// ConfigHashAnnotation is set on the pod template of each component, so that its pods are rolled out whenever the
+// effective configuration of the component changes.
+const ConfigHashAnnotation = "splunk-otel-operator-config/sha256"
+
+// operatorAnnotationPrefix prefixes the annotations of the instance meant for the operator, like the ones pausing
+// its reconciliation, which aren't copied to the pod templates.
+const operatorAnnotationPrefix = "otel.splunk.com/"
+
+// Annotations return the annotations for the SplunkOtelAgent workloads.
 func Annotations(instance v1alpha1.Agent) map[string]string {
 	// new map every time, so that we don't touch the instance's annotations
 	annotations := map[string]string{}
@@ -38,13 +48,104 @@ func Annotations(instance v1alpha1.Agent) map[string]string {
 			annotations[k] = v
 		}
 	}
-	// make sure sha256 for configMap is always calculated
-	annotations["splunk-otel-operator-config/sha256"] = getConfigMapSHA(instance.Spec.Agent.Config)
+	// the config hash belongs to the pod template, see PodAnnotations
+	delete(annotations, ConfigHashAnnotation)
+
+	return annotations
+}
+
+// PodAnnotations return the annotations for the pod template of the named component, including the hash of its
+// configuration. The hash only covers the spec here, the reconciler adds the referenced Secrets and ConfigMaps to it.
+// Annotations meant for the operator are left out, so that setting them doesn't roll the pods out.
+func PodAnnotations(instance v1alpha1.Agent, component string) map[string]string {
+	annotations := map[string]string{}
+	for k, v := range instance.Annotations {
+		if !strings.HasPrefix(k, operatorAnnotationPrefix) {
+			annotations[k] = v
+		}
+	}
 
+	annotations[ConfigHashAnnotation] = ConfigHash(*instance.CollectorSpec(component), nil)
 	return annotations
 }
 
-func getConfigMapSHA(config string) string {
-	h := sha256.Sum256([]byte(config))
-	return fmt.Sprintf("%x", h)
+// ConfigHash returns the hash of the effective configuration of a component: its config, its config overlay and the
+// data of the Secrets and ConfigMaps it references, keyed by object as returned by ReferencedObjects.
+func ConfigHash(spec v1alpha1.CollectorSpec, referenced map[string]map[string][]byte) string {
+	h := sha256.New()
+	h.Write([]byte(spec.Config + spec.ConfigOverlay))
+
+	objects := make([]string, 0, len(referenced))
+	for object := range referenced {
+		objects = append(objects, object)
+	}
+	sort.Strings(objects)
+
+	for _, object := range objects {
+		data := referenced[object]
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		fmt.Fprintf(h, "\n%s", object)
+		for _, k := range keys {
+			fmt.Fprintf(h, "\n%s=", k)
+			h.Write(data[k])
+		}
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// ReferencedObjects returns the names of the Secrets and ConfigMaps the component reads through its env vars and
+// volumes. The config map managed by the operator isn't part of the spec and therefore never returned.
+func ReferencedObjects(spec v1alpha1.CollectorSpec) (secrets []string, configMaps []string) {
+	for _, env := range spec.Env {
+		if env.ValueFrom == nil {
+			continue
+		}
+		if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+			secrets = append(secrets, ref.Name)
+		}
+		if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+			configMaps = append(configMaps, ref.Name)
+		}
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil {
+			secrets = append(secrets, volume.Secret.SecretName)
+		}
+		if volume.ConfigMap != nil {
+			configMaps = append(configMaps, volume.ConfigMap.Name)
+		}
+		if volume.Projected == nil {
+			continue
+		}
+		for _, source := range volume.Projected.Sources {
+			if source.Secret != nil {
+				secrets = append(secrets, source.Secret.Name)
+			}
+			if source.ConfigMap != nil {
+				configMaps = append(configMaps, source.ConfigMap.Name)
+			}
+		}
+	}
+
+	return unique(secrets), unique(configMaps)
+}
+
+func unique(names []string) []string {
+	var res []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		if len(name) == 0 || seen[name] {
+			continue
+		}
+		seen[name] = true
+		res = append(res, name)
+	}
+	return res
 }
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
@@ -43,7 +44,7 @@ func TestDefaultAnnotations(t *testing.T) {
 	assert.Equal(t, "true", annotations["prometheus.io/scrape"])
 	assert.Equal(t, "8888", annotations["prometheus.io/port"])
 	assert.Equal(t, "/metrics", annotations["prometheus.io/path"])
-	assert.Equal(t, "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", annotations["splunk-otel-operator-config/sha256"])
+	assert.NotContains(t, annotations, ConfigHashAnnotation)
 }
 
 func TestUserAnnotations(t *testing.T) {
@@ -70,7 +71,7 @@ func TestUserAnnotations(t *testing.T) {
 	assert.Equal(t, "false", annotations["prometheus.io/scrape"])
 	assert.Equal(t, "1234", annotations["prometheus.io/port"])
 	assert.Equal(t, "/test", annotations["prometheus.io/path"])
-	assert.Equal(t, "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", annotations["splunk-otel-operator-config/sha256"])
+	assert.NotContains(t, annotations, ConfigHashAnnotation)
 }
 
 func TestAnnotationsPropagateDown(t *testing.T) {
@@ -85,6 +86,128 @@ func TestAnnotationsPropagateDown(t *testing.T) {
 	annotations := Annotations(otelcol)
 
 	// verify
-	assert.Len(t, annotations, 5)
+	assert.Len(t, annotations, 4)
 	assert.Equal(t, "mycomponent", annotations["myapp"])
 }
+
+func TestPodAnnotations(t *testing.T) {
+	// prepare
+	otelcol := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{"myapp": "mycomponent", ConfigHashAnnotation: "shouldBeOverwritten"},
+		},
+		Spec: v1alpha1.AgentSpec{Agent: v1alpha1.CollectorSpec{
+			Config: "test",
+		}},
+	}
+
+	// test
+	annotations := PodAnnotations(otelcol, "agent")
+
+	// verify
+	assert.Equal(t, "mycomponent", annotations["myapp"])
+	assert.Equal(t, "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", annotations[ConfigHashAnnotation])
+	assert.NotContains(t, annotations, "prometheus.io/scrape")
+}
+
+func TestPodAnnotationsExcludeOperatorAnnotations(t *testing.T) {
+	// prepare
+	otelcol := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{"myapp": "mycomponent"},
+		},
+		Spec: v1alpha1.AgentSpec{Agent: v1alpha1.CollectorSpec{
+			Config: "test",
+		}},
+	}
+	before := PodAnnotations(otelcol, "agent")
+
+	// test
+	otelcol.Annotations = map[string]string{
+		"myapp": "mycomponent",
+		v1alpha1.AnnotationReconcile + "-gateway": "paused",
+		"otel.splunk.com/upgrade-dry-run":         "true",
+		v1alpha1.DefaultConfigAnnotation("agent"): "c0ffee",
+	}
+	after := PodAnnotations(otelcol, "agent")
+
+	// verify
+	assert.Equal(t, before, after)
+}
+
+func TestConfigSHAPerComponent(t *testing.T) {
+	// prepare
+	otelcol := v1alpha1.Agent{
+		Spec: v1alpha1.AgentSpec{
+			Agent:   v1alpha1.CollectorSpec{Config: "agent"},
+			Gateway: v1alpha1.CollectorSpec{Config: "gateway"},
+		},
+	}
+	agent := PodAnnotations(otelcol, "agent")[ConfigHashAnnotation]
+
+	// test
+	otelcol.Spec.Gateway.Config = "updated"
+
+	// verify
+	assert.Equal(t, agent, PodAnnotations(otelcol, "agent")[ConfigHashAnnotation])
+	assert.NotEqual(t, PodAnnotations(otelcol, "gateway")[ConfigHashAnnotation], PodAnnotations(otelcol, "cluster-receiver")[ConfigHashAnnotation])
+}
+
+func TestConfigOverlayChangesConfigSHA(t *testing.T) {
+	// prepare
+	otelcol := v1alpha1.Agent{
+		Spec: v1alpha1.AgentSpec{Agent: v1alpha1.CollectorSpec{
+			Config: "test",
+		}},
+	}
+	withoutOverlay := PodAnnotations(otelcol, "agent")[ConfigHashAnnotation]
+
+	// test
+	otelcol.Spec.Agent.ConfigOverlay = "receivers:\n  otlp:\n"
+	withOverlay := PodAnnotations(otelcol, "agent")[ConfigHashAnnotation]
+
+	// verify
+	assert.NotEqual(t, withoutOverlay, withOverlay)
+}
+
+func TestReferencedDataChangesConfigSHA(t *testing.T) {
+	spec := v1alpha1.CollectorSpec{Config: "test"}
+	referenced := map[string]map[string][]byte{"secret/token": {"access-token": []byte("old")}}
+	before := ConfigHash(spec, referenced)
+
+	referenced["secret/token"]["access-token"] = []byte("new")
+
+	assert.NotEqual(t, before, ConfigHash(spec, referenced))
+	assert.Equal(t, ConfigHash(spec, nil), ConfigHash(spec, map[string]map[string][]byte{}))
+}
+
+func TestReferencedObjects(t *testing.T) {
+	// prepare
+	spec := v1alpha1.CollectorSpec{
+		Env: []corev1.EnvVar{
+			{Name: "PLAIN", Value: "value"},
+			{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "token"}, Key: "access-token"},
+			}},
+			{Name: "REALM", ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}, Key: "realm"},
+			}},
+		},
+		Volumes: []corev1.Volume{
+			{Name: "certs", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "certs"}}},
+			{Name: "projected", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "token"}}},
+					{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "extra"}}},
+				},
+			}}},
+		},
+	}
+
+	// test
+	secrets, configMaps := ReferencedObjects(spec)
+
+	// verify
+	assert.Equal(t, []string{"token", "certs"}, secrets)
+	assert.Equal(t, []string{"settings", "extra"}, configMaps)
+}
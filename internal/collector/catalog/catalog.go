@@ -0,0 +1,97 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package catalog lists the components shipped in each release of the Splunk OpenTelemetry Collector.
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sections are the config sections listing components, in the order they're reported.
+var sections = []string{"extensions", "receivers", "processors", "exporters", "connectors"}
+
+// officialRepositories are the repositories of the images the catalog knows about. Other images may be custom builds
+// shipping different components, so they can't be checked.
+var officialRepositories = []string{"quay.io/signalfx/splunk-otel-collector"}
+
+// Components are the component types shipped in a collector release, by config section.
+type Components map[string][]string
+
+// releases are the catalogs of the known collector releases, keyed by the versions used in versions.txt.
+var releases = map[string]Components{
+	"0.71.0": v0_71_0,
+}
+
+// For returns the components shipped in the given collector release, if the release is known.
+func For(release string) (Components, bool) {
+	components, ok := releases[strings.TrimPrefix(release, "v")]
+	return components, ok
+}
+
+// ReleaseOf returns the collector release of the given image, which has to be an official image referenced by tag.
+func ReleaseOf(image string) (string, bool) {
+	// the digest pins the image, the tag is only informative then
+	image, _, _ = strings.Cut(image, "@")
+
+	sep := strings.LastIndex(image, ":")
+	if sep < 0 || strings.Contains(image[sep:], "/") {
+		return "", false
+	}
+
+	repository, tag := image[:sep], image[sep+1:]
+	for _, official := range officialRepositories {
+		if repository == official {
+			return strings.TrimPrefix(tag, "v"), true
+		}
+	}
+	return "", false
+}
+
+// Missing returns the components used by the configuration that aren't shipped in the release, like `receiver "foo"`.
+func (c Components) Missing(config map[interface{}]interface{}) []string {
+	var missing []string
+	for _, section := range sections {
+		components, ok := config[section].(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+
+		var names []string
+		for k := range components {
+			name := fmt.Sprintf("%v", k)
+			componentType, _, _ := strings.Cut(name, "/")
+			if !c.ships(section, componentType) {
+				names = append(names, name)
+			}
+		}
+
+		sort.Strings(names)
+		for _, name := range names {
+			missing = append(missing, fmt.Sprintf("%s %q", strings.TrimSuffix(section, "s"), name))
+		}
+	}
+	return missing
+}
+
+func (c Components) ships(section, componentType string) bool {
+	for _, t := range c[section] {
+		if t == componentType {
+			return true
+		}
+	}
+	return false
+}
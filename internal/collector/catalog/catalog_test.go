@@ -0,0 +1,77 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/adapters"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/catalog"
+)
+
+func TestReleaseOf(t *testing.T) {
+	for _, tt := range []struct {
+		image    string
+		release  string
+		official bool
+	}{
+		{image: "quay.io/signalfx/splunk-otel-collector:0.71.0", release: "0.71.0", official: true},
+		{image: "quay.io/signalfx/splunk-otel-collector:v0.71.0", release: "0.71.0", official: true},
+		{image: "quay.io/signalfx/splunk-otel-collector:0.71.0@sha256:0123456789abcdef", release: "0.71.0", official: true},
+		{image: "quay.io/signalfx/splunk-otel-collector@sha256:0123456789abcdef"},
+		{image: "quay.io/signalfx/splunk-otel-collector"},
+		{image: "localhost:5000/splunk-otel-collector"},
+		{image: "otel/opentelemetry-collector-contrib:0.71.0"},
+	} {
+		t.Run(tt.image, func(t *testing.T) {
+			release, official := catalog.ReleaseOf(tt.image)
+			assert.Equal(t, tt.release, release)
+			assert.Equal(t, tt.official, official)
+		})
+	}
+}
+
+func TestMissing(t *testing.T) {
+	// prepare
+	components, ok := catalog.For("0.71.0")
+	require.True(t, ok)
+
+	config, err := adapters.ConfigFromString(`receivers:
+  otlp:
+  prometheus/self:
+  awsxray:
+  nginx/1:
+processors:
+  batch:
+exporters:
+  signalfx:
+  debug:
+`)
+	require.NoError(t, err)
+
+	// test
+	missing := components.Missing(config)
+
+	// verify
+	assert.Equal(t, []string{`receiver "awsxray"`, `receiver "nginx/1"`, `exporter "debug"`}, missing)
+}
+
+func TestUnknownRelease(t *testing.T) {
+	_, ok := catalog.For("0.0.0")
+	assert.False(t, ok)
+}
@@ -0,0 +1,104 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+// v0_71_0 mirrors internal/components/components.go of the v0.71.0 release of the Splunk OpenTelemetry Collector.
+var v0_71_0 = Components{
+	"extensions": {
+		"basicauth",
+		"bearertokenauth",
+		"docker_observer",
+		"ecs_observer",
+		"ecs_task_observer",
+		"file_storage",
+		"health_check",
+		"host_observer",
+		"http_forwarder",
+		"k8s_observer",
+		"memory_ballast",
+		"oauth2client",
+		"pprof",
+		"smartagent",
+		"zpages",
+	},
+	"receivers": {
+		"carbon",
+		"cloudfoundry",
+		"collectd",
+		"discovery",
+		"filelog",
+		"fluentforward",
+		"hostmetrics",
+		"jaeger",
+		"jmx",
+		"journald",
+		"k8s_cluster",
+		"k8s_events",
+		"k8sobjects",
+		"kafka",
+		"kafkametrics",
+		"kubeletstats",
+		"mongodbatlas",
+		"oracledb",
+		"otlp",
+		"postgresql",
+		"prometheus",
+		"prometheus_simple",
+		"receiver_creator",
+		"redis",
+		"sapm",
+		"signalfx",
+		"smartagent",
+		"splunk_hec",
+		"sqlquery",
+		"statsd",
+		"syslog",
+		"tcplog",
+		"udplog",
+		"windowseventlog",
+		"windowsperfcounters",
+		"zipkin",
+	},
+	"processors": {
+		"attributes",
+		"batch",
+		"cumulativetodelta",
+		"filter",
+		"groupbyattrs",
+		"k8sattributes",
+		"logstransform",
+		"memory_limiter",
+		"metricstransform",
+		"probabilistic_sampler",
+		"resource",
+		"resourcedetection",
+		"routing",
+		"span",
+		"spanmetrics",
+		"tail_sampling",
+		"transform",
+	},
+	"exporters": {
+		"file",
+		"kafka",
+		"loadbalancing",
+		"logging",
+		"otlp",
+		"otlphttp",
+		"sapm",
+		"signalfx",
+		"splunk_hec",
+	},
+}
@@ -47,10 +47,10 @@ func ClusterReceiver(logger logr.Logger, otelcol v1alpha1.Agent) appsv1.Deployme
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels:      labels,
-					Annotations: otelcol.Annotations,
+					Annotations: PodAnnotations(otelcol, "cluster-receiver"),
 				},
 				Spec: corev1.PodSpec{
-					ServiceAccountName: ServiceAccountName(otelcol),
+					ServiceAccountName: ServiceAccountName(otelcol, "cluster-receiver"),
 					Containers:         []corev1.Container{Container(logger, otelcol.Spec.ClusterReceiver)},
 					Volumes:            Volumes(otelcol.Spec.ClusterReceiver, naming.ConfigMap(otelcol, "cluster-receiver")),
 					Tolerations:        otelcol.Spec.ClusterReceiver.Tolerations,
@@ -47,10 +47,10 @@ func Gateway(logger logr.Logger, otelcol v1alpha1.Agent) appsv1.Deployment {
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels:      labels,
-					Annotations: otelcol.Annotations,
+					Annotations: PodAnnotations(otelcol, "gateway"),
 				},
 				Spec: corev1.PodSpec{
-					ServiceAccountName: ServiceAccountName(otelcol),
+					ServiceAccountName: ServiceAccountName(otelcol, "gateway"),
 					Containers:         []corev1.Container{Container(logger, otelcol.Spec.Gateway)},
 					Volumes:            Volumes(otelcol.Spec.Gateway, naming.ConfigMap(otelcol, "gateway")),
 					Tolerations:        otelcol.Spec.Gateway.Tolerations,
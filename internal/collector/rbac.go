@@ -0,0 +1,154 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/adapters"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/naming"
+)
+
+// commonRules are granted to every component regardless of its config. Scraping the metrics of the API server, like
+// prometheus receivers do, isn't tied to a component type.
+var commonRules = []rbacv1.PolicyRule{
+	{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}},
+}
+
+// baseRules are granted to a component regardless of its config.
+var baseRules = map[string][]rbacv1.PolicyRule{
+	// the agent runs with host access on OpenShift, the rule is ignored by other distributions
+	"agent": {
+		{APIGroups: []string{"security.openshift.io"}, Resources: []string{"securitycontextconstraints"},
+			ResourceNames: []string{"splunk-otel-agent"}, Verbs: []string{"use"}},
+	},
+}
+
+// ClusterRole returns the cluster role granting the given component of the instance what its config needs, like
+// read access to the nodes for the kubeletstats receiver. A config that can't be parsed only gets the base rules.
+func ClusterRole(logger logr.Logger, otelcol v1alpha1.Agent, component string) rbacv1.ClusterRole {
+	labels := Labels(otelcol)
+	labels["app.kubernetes.io/name"] = naming.ClusterRole(otelcol, component)
+
+	rules := []rbacv1.PolicyRule{}
+	for _, rule := range append(append([]rbacv1.PolicyRule{}, commonRules...), baseRules[component]...) {
+		rules = append(rules, *rule.DeepCopy())
+	}
+
+	spec := otelcol.CollectorSpec(component)
+	config, err := effectiveConfig(*spec)
+	if err != nil {
+		logger.Error(err, "couldn't derive the permissions from the config", "component", component)
+	} else {
+		rules = append(rules, adapters.ConfigToRBACRules(logger, config)...)
+	}
+
+	return rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        naming.ClusterRole(otelcol, component),
+			Labels:      labels,
+			Annotations: otelcol.Annotations,
+		},
+		Rules: rules,
+	}
+}
+
+func effectiveConfig(spec v1alpha1.CollectorSpec) (map[interface{}]interface{}, error) {
+	configStr, err := adapters.ConfigWithOverlay(spec.Config, spec.ConfigOverlay)
+	if err != nil {
+		return nil, err
+	}
+	return adapters.ConfigFromString(configStr)
+}
+
+// ClusterRoleBinding returns the binding of the cluster role of the given component to the service account it runs
+// with, which might be one provided by the user.
+func ClusterRoleBinding(otelcol v1alpha1.Agent, component string) rbacv1.ClusterRoleBinding {
+	labels := Labels(otelcol)
+	labels["app.kubernetes.io/name"] = naming.ClusterRole(otelcol, component)
+
+	return rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        naming.ClusterRole(otelcol, component),
+			Labels:      labels,
+			Annotations: otelcol.Annotations,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     naming.ClusterRole(otelcol, component),
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      ServiceAccountName(otelcol, component),
+			Namespace: otelcol.Namespace,
+		}},
+	}
+}
+
+// Role returns the role granting the given component of the instance what its config needs in the namespace of the
+// instance only, like the config maps some receivers elect a leader with. It has no rules when the config needs none,
+// or can't be parsed.
+func Role(logger logr.Logger, otelcol v1alpha1.Agent, component string) rbacv1.Role {
+	labels := Labels(otelcol)
+	labels["app.kubernetes.io/name"] = naming.Role(otelcol, component)
+
+	var rules []rbacv1.PolicyRule
+	spec := otelcol.CollectorSpec(component)
+	config, err := effectiveConfig(*spec)
+	if err != nil {
+		logger.Error(err, "couldn't derive the permissions from the config", "component", component)
+	} else {
+		rules = adapters.ConfigToNamespacedRBACRules(logger, config)
+	}
+
+	return rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        naming.Role(otelcol, component),
+			Namespace:   otelcol.Namespace,
+			Labels:      labels,
+			Annotations: otelcol.Annotations,
+		},
+		Rules: rules,
+	}
+}
+
+// RoleBinding returns the binding of the role of the given component to the service account it runs with.
+func RoleBinding(otelcol v1alpha1.Agent, component string) rbacv1.RoleBinding {
+	labels := Labels(otelcol)
+	labels["app.kubernetes.io/name"] = naming.Role(otelcol, component)
+
+	return rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        naming.Role(otelcol, component),
+			Namespace:   otelcol.Namespace,
+			Labels:      labels,
+			Annotations: otelcol.Annotations,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     naming.Role(otelcol, component),
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      ServiceAccountName(otelcol, component),
+			Namespace: otelcol.Namespace,
+		}},
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func init() {
+	Register("extensions", "k8s_observer", k8sObserverRules)
+}
+
+// k8sObserverRules grants the k8s_observer read access to the kinds of endpoints it observes. Pods are observed
+// unless disabled, the other kinds only when enabled.
+func k8sObserverRules(_ logr.Logger, _ string, config map[interface{}]interface{}) []rbacv1.PolicyRule {
+	var rules []rbacv1.PolicyRule
+	if config["observe_pods"] != false {
+		rules = append(rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: readOnly})
+	}
+	if config["observe_nodes"] == true {
+		rules = append(rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: readOnly})
+	}
+	if config["observe_services"] == true {
+		rules = append(rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"services"}, Verbs: readOnly})
+	}
+	if config["observe_ingresses"] == true {
+		rules = append(rules, rbacv1.PolicyRule{APIGroups: []string{"networking.k8s.io"}, Resources: []string{"ingresses"}, Verbs: readOnly})
+	}
+	return rules
+}
@@ -0,0 +1,51 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestK8sObserverRules(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		config   map[interface{}]interface{}
+		expected []rbacv1.PolicyRule
+	}{
+		{
+			"observes pods by default",
+			map[interface{}]interface{}{},
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: readOnly}},
+		},
+		{
+			"observes nodes only",
+			map[interface{}]interface{}{"observe_pods": false, "observe_nodes": true},
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: readOnly}},
+		},
+		{
+			"observes ingresses",
+			map[interface{}]interface{}{"observe_pods": false, "observe_ingresses": true},
+			[]rbacv1.PolicyRule{{APIGroups: []string{"networking.k8s.io"}, Resources: []string{"ingresses"}, Verbs: readOnly}},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			// test and verify
+			assert.Equal(t, tt.expected, For(logger, "extensions", "k8s_observer", tt.config))
+		})
+	}
+}
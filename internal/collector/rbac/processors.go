@@ -0,0 +1,52 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func init() {
+	Register("processors", "k8sattributes", k8sAttributesRules)
+	Register("processors", "resourcedetection", resourceDetectionRules)
+}
+
+// k8sAttributesRules grants the k8sattributes processor read access to the metadata it decorates the data with. In
+// passthrough mode the processor only records the pod IP and doesn't talk to the API.
+func k8sAttributesRules(_ logr.Logger, _ string, config map[interface{}]interface{}) []rbacv1.PolicyRule {
+	if config["passthrough"] == true {
+		return nil
+	}
+
+	return []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"namespaces", "pods"}, Verbs: readOnly},
+		{APIGroups: []string{"apps"}, Resources: []string{"replicasets"}, Verbs: readOnly},
+	}
+}
+
+// resourceDetectionRules grants the resourcedetection processor read access to the nodes when the k8snode detector is
+// enabled, the other detectors don't talk to the API.
+func resourceDetectionRules(_ logr.Logger, _ string, config map[interface{}]interface{}) []rbacv1.PolicyRule {
+	detectors, _ := config["detectors"].([]interface{})
+	for _, detector := range detectors {
+		if detector == "k8snode" {
+			return []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: readOnly},
+			}
+		}
+	}
+	return nil
+}
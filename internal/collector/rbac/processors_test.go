@@ -0,0 +1,35 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestK8sAttributesRules(t *testing.T) {
+	assert.Len(t, For(logger, "processors", "k8sattributes", map[interface{}]interface{}{}), 2)
+	assert.Empty(t, For(logger, "processors", "k8sattributes/passthrough", map[interface{}]interface{}{"passthrough": true}))
+}
+
+func TestResourceDetectionRules(t *testing.T) {
+	assert.Empty(t, For(logger, "processors", "resourcedetection", map[interface{}]interface{}{
+		"detectors": []interface{}{"env", "system"},
+	}))
+	assert.Len(t, For(logger, "processors", "resourcedetection", map[interface{}]interface{}{
+		"detectors": []interface{}{"env", "k8snode"},
+	}), 1)
+}
@@ -0,0 +1,238 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func init() {
+	Register("receivers", "k8s_cluster", k8sClusterRules)
+	Register("receivers", "k8s_events", k8sEventsRules)
+	Register("receivers", "k8sobjects", k8sObjectsRules)
+	Register("receivers", "kubeletstats", kubeletStatsRules)
+	Register("receivers", "receiver_creator", receiverCreatorRules)
+	Register("receivers", "smartagent", smartAgentRules)
+
+	RegisterNamespaced("receivers", "receiver_creator", receiverCreatorNamespacedRules)
+	RegisterNamespaced("receivers", "smartagent", smartAgentNamespacedRules)
+}
+
+// k8sClusterRules grants the k8s_cluster receiver read access to the objects it reports the state of.
+func k8sClusterRules(_ logr.Logger, _ string, config map[interface{}]interface{}) []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{
+			"events",
+			"namespaces",
+			"namespaces/status",
+			"nodes",
+			"nodes/spec",
+			"pods",
+			"pods/status",
+			"replicationcontrollers",
+			"replicationcontrollers/status",
+			"resourcequotas",
+			"services",
+		}, Verbs: readOnly},
+		{APIGroups: []string{"apps"}, Resources: []string{"daemonsets", "deployments", "replicasets", "statefulsets"}, Verbs: readOnly},
+		{APIGroups: []string{"extensions"}, Resources: []string{"daemonsets", "deployments", "replicasets"}, Verbs: readOnly},
+		{APIGroups: []string{"batch"}, Resources: []string{"jobs", "cronjobs"}, Verbs: readOnly},
+		{APIGroups: []string{"autoscaling"}, Resources: []string{"horizontalpodautoscalers"}, Verbs: readOnly},
+	}
+
+	if config["distribution"] == "openshift" {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{"quota.openshift.io"}, Resources: []string{"clusterresourcequotas"}, Verbs: readOnly,
+		})
+	}
+	return rules
+}
+
+// k8sEventsRules grants the k8s_events receiver read access to the events and the namespaces they're in.
+func k8sEventsRules(_ logr.Logger, _ string, _ map[interface{}]interface{}) []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"events", "namespaces"}, Verbs: readOnly},
+	}
+}
+
+// allowedObjects are the objects the k8sobjects receiver can be granted read access to, by API group. The operator can
+// grant any permission, so anything else, like secrets, config maps or the permissions of others, is never granted.
+var allowedObjects = map[string][]string{
+	"":              {"events", "namespaces", "nodes", "pods"},
+	"apps":          {"daemonsets", "deployments", "replicasets", "statefulsets"},
+	"batch":         {"cronjobs", "jobs"},
+	"events.k8s.io": {"events"},
+}
+
+// k8sObjectsRules grants the k8sobjects receiver read access to each of the configured objects that is allowed.
+// Objects without a group are looked up in the core group.
+func k8sObjectsRules(logger logr.Logger, name string, config map[interface{}]interface{}) []rbacv1.PolicyRule {
+	var rules []rbacv1.PolicyRule
+	for _, object := range k8sObjects(logger, name, config) {
+		if !isAllowedObject(object.group, object.resource) {
+			logger.Info("access to object is denied", "receiver", name, "group", object.group, "object", object.resource)
+			continue
+		}
+		rules = append(rules, rbacv1.PolicyRule{APIGroups: []string{object.group}, Resources: []string{object.resource}, Verbs: readOnly})
+	}
+	return rules
+}
+
+// DeniedObjects returns the objects the named component is configured to read but is never granted access to, like
+// "secrets" or "clusterroles.rbac.authorization.k8s.io" for a k8sobjects receiver.
+func DeniedObjects(section, name string, config map[interface{}]interface{}) []string {
+	if section != "receivers" || componentType(name) != "k8sobjects" {
+		return nil
+	}
+
+	var denied []string
+	for _, object := range k8sObjects(logr.Discard(), name, config) {
+		if isAllowedObject(object.group, object.resource) {
+			continue
+		}
+		if object.group == "" {
+			denied = append(denied, object.resource)
+		} else {
+			denied = append(denied, object.resource+"."+object.group)
+		}
+	}
+	return denied
+}
+
+type k8sObject struct {
+	group    string
+	resource string
+}
+
+// k8sObjects returns the objects listed by the config of a k8sobjects receiver, skipping the ones without a name.
+func k8sObjects(logger logr.Logger, name string, config map[interface{}]interface{}) []k8sObject {
+	objects, ok := config["objects"].([]interface{})
+	if !ok {
+		logger.V(2).Info("receiver doesn't have a list of objects", "receiver", name)
+		return nil
+	}
+
+	var res []k8sObject
+	for _, o := range objects {
+		object, ok := o.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+		resource, ok := object["name"].(string)
+		if !ok || len(resource) == 0 {
+			logger.Info("object without a name is ignored", "receiver", name)
+			continue
+		}
+		group, _ := object["group"].(string)
+		res = append(res, k8sObject{group: group, resource: resource})
+	}
+	return res
+}
+
+func isAllowedObject(group, resource string) bool {
+	for _, allowed := range allowedObjects[group] {
+		if allowed == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// kubeletStatsRules grants the kubeletstats receiver access to the stats served by the kubelet of its node, and read
+// access to the volumes when it reports their metadata.
+func kubeletStatsRules(_ logr.Logger, _ string, config map[interface{}]interface{}) []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: readOnly},
+		{APIGroups: []string{""}, Resources: []string{"nodes/stats", "nodes/proxy"}, Verbs: []string{"get"}},
+	}
+
+	labels, _ := config["extra_metadata_labels"].([]interface{})
+	for _, label := range labels {
+		if label == "k8s.volume.type" {
+			rules = append(rules, volumeRules...)
+			break
+		}
+	}
+	return rules
+}
+
+// volumeRules grant read access to the volumes and their claims.
+var volumeRules = []rbacv1.PolicyRule{
+	{APIGroups: []string{""}, Resources: []string{"persistentvolumeclaims", "persistentvolumes"}, Verbs: readOnly},
+}
+
+// smartAgentRules grants the smartagent receiver what its monitor needs across the cluster. The volumes monitor reads
+// the volumes it reports on.
+func smartAgentRules(logger logr.Logger, name string, config map[interface{}]interface{}) []rbacv1.PolicyRule {
+	switch config["type"] {
+	case "kubernetes-cluster":
+		return k8sClusterRules(logger, name, config)
+	case "openshift-cluster":
+		return k8sClusterRules(logger, name, map[interface{}]interface{}{"distribution": "openshift"})
+	case "kubernetes-events":
+		return k8sEventsRules(logger, name, config)
+	case "kubernetes-volumes":
+		return append(kubeletStatsRules(logger, name, config), volumeRules...)
+	}
+	return nil
+}
+
+// smartAgentNamespacedRules grants the cluster and events monitors of the smartagent receiver the config maps they
+// elect the collector reporting for the cluster with, in the namespace of the collector.
+func smartAgentNamespacedRules(_ logr.Logger, _ string, config map[interface{}]interface{}) []rbacv1.PolicyRule {
+	switch config["type"] {
+	case "kubernetes-cluster", "openshift-cluster", "kubernetes-events":
+		return []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "update", "create"}},
+		}
+	}
+	return nil
+}
+
+// receiverCreatorRules grants the receiver_creator what the receivers it starts need. The observers it watches are
+// extensions and get their own rules.
+func receiverCreatorRules(logger logr.Logger, _ string, config map[interface{}]interface{}) []rbacv1.PolicyRule {
+	return createdReceiversRules(logger, config, For)
+}
+
+// receiverCreatorNamespacedRules grants the receiver_creator what the receivers it starts need in the namespace of the
+// collector.
+func receiverCreatorNamespacedRules(logger logr.Logger, _ string, config map[interface{}]interface{}) []rbacv1.PolicyRule {
+	return createdReceiversRules(logger, config, NamespacedFor)
+}
+
+// createdReceiversRules returns the rules the lookup function gives for each receiver template of a receiver_creator.
+func createdReceiversRules(logger logr.Logger, config map[interface{}]interface{}, lookup func(logr.Logger, string, string, map[interface{}]interface{}) []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	receivers, ok := config["receivers"].(map[interface{}]interface{})
+	if !ok {
+		return nil
+	}
+
+	var rules []rbacv1.PolicyRule
+	for k, v := range receivers {
+		name, ok := k.(string)
+		if !ok {
+			continue
+		}
+		template, _ := v.(map[interface{}]interface{})
+		receiverConfig, _ := template["config"].(map[interface{}]interface{})
+		if receiverConfig == nil {
+			receiverConfig = map[interface{}]interface{}{}
+		}
+		rules = append(rules, lookup(logger, "receivers", name, receiverConfig)...)
+	}
+	return rules
+}
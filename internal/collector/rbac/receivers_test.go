@@ -0,0 +1,184 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestK8sClusterRules(t *testing.T) {
+	// test
+	rules := For(logger, "receivers", "k8s_cluster", map[interface{}]interface{}{})
+	openshift := For(logger, "receivers", "k8s_cluster", map[interface{}]interface{}{"distribution": "openshift"})
+
+	// verify
+	assert.Contains(t, rules[0].Resources, "events")
+	assert.Len(t, openshift, len(rules)+1)
+	assert.Equal(t, []string{"quota.openshift.io"}, openshift[len(openshift)-1].APIGroups)
+}
+
+func TestK8sObjectsRules(t *testing.T) {
+	// prepare
+	config := map[interface{}]interface{}{}
+	err := yaml.Unmarshal([]byte(`
+objects:
+  - name: pods
+    mode: pull
+  - name: events
+    group: events.k8s.io
+    mode: watch
+  - mode: watch
+`), &config)
+	assert.NoError(t, err)
+
+	// test
+	rules := For(logger, "receivers", "k8sobjects", config)
+
+	// verify
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: readOnly},
+		{APIGroups: []string{"events.k8s.io"}, Resources: []string{"events"}, Verbs: readOnly},
+	}, rules)
+}
+
+func TestK8sObjectsRulesDenied(t *testing.T) {
+	// prepare
+	config := map[interface{}]interface{}{}
+	err := yaml.Unmarshal([]byte(`
+objects:
+  - name: secrets
+  - name: serviceaccounts/token
+  - name: clusterroles
+    group: rbac.authorization.k8s.io
+  - name: "*"
+  - name: pods
+    group: "*"
+  - name: configmaps
+  - name: nodes/proxy
+  - name: customresourcedefinitions
+    group: apiextensions.k8s.io
+  - name: deployments
+    group: apps
+`), &config)
+	assert.NoError(t, err)
+
+	// test
+	rules := For(logger, "receivers", "k8sobjects", config)
+	denied := DeniedObjects("receivers", "k8sobjects/custom", config)
+
+	// verify
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: readOnly},
+	}, rules)
+	assert.Equal(t, []string{
+		"secrets",
+		"serviceaccounts/token",
+		"clusterroles.rbac.authorization.k8s.io",
+		"*",
+		"pods.*",
+		"configmaps",
+		"nodes/proxy",
+		"customresourcedefinitions.apiextensions.k8s.io",
+	}, denied)
+	assert.Empty(t, DeniedObjects("receivers", "k8s_cluster", config), "only the k8sobjects receiver lists objects")
+}
+
+func TestKubeletStatsRulesVolumes(t *testing.T) {
+	// prepare
+	config := map[interface{}]interface{}{}
+	err := yaml.Unmarshal([]byte(`
+extra_metadata_labels:
+  - container.id
+  - k8s.volume.type
+`), &config)
+	assert.NoError(t, err)
+
+	// test
+	rules := For(logger, "receivers", "kubeletstats", config)
+
+	// verify
+	assert.NotContains(t, For(logger, "receivers", "kubeletstats", nil), volumeRules[0])
+	assert.Contains(t, rules, volumeRules[0])
+}
+
+func TestSmartAgentRules(t *testing.T) {
+	for _, tt := range []struct {
+		monitor  string
+		expected string
+	}{
+		{monitor: "kubernetes-cluster", expected: "nodes"},
+		{monitor: "openshift-cluster", expected: "clusterresourcequotas"},
+		{monitor: "kubernetes-events", expected: "events"},
+		{monitor: "kubernetes-volumes", expected: "persistentvolumes"},
+	} {
+		t.Run(tt.monitor, func(t *testing.T) {
+			// test
+			rules := For(logger, "receivers", "smartagent/"+tt.monitor, map[interface{}]interface{}{"type": tt.monitor})
+
+			// verify
+			var resources []string
+			for _, rule := range rules {
+				resources = append(resources, rule.Resources...)
+			}
+			assert.Contains(t, resources, tt.expected)
+			assert.NotContains(t, resources, "configmaps", "the config maps should only be granted in the namespace")
+		})
+	}
+
+	// a forwarder doesn't talk to the API
+	assert.Empty(t, For(logger, "receivers", "smartagent/signalfx-forwarder", map[interface{}]interface{}{"type": "signalfx-forwarder"}))
+}
+
+func TestSmartAgentNamespacedRules(t *testing.T) {
+	leaderElection := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "update", "create"}},
+	}
+	for _, monitor := range []string{"kubernetes-cluster", "openshift-cluster", "kubernetes-events"} {
+		t.Run(monitor, func(t *testing.T) {
+			// test and verify
+			assert.Equal(t, leaderElection, NamespacedFor(logger, "receivers", "smartagent/"+monitor, map[interface{}]interface{}{"type": monitor}))
+		})
+	}
+
+	assert.Empty(t, NamespacedFor(logger, "receivers", "smartagent/volumes", map[interface{}]interface{}{"type": "kubernetes-volumes"}))
+	assert.Empty(t, NamespacedFor(logger, "receivers", "k8s_cluster", map[interface{}]interface{}{}))
+}
+
+func TestReceiverCreatorRules(t *testing.T) {
+	// prepare
+	config := map[interface{}]interface{}{}
+	err := yaml.Unmarshal([]byte(`
+watch_observers:
+  - k8s_observer
+receivers:
+  kubeletstats:
+    rule: type == "k8s.node"
+    config:
+      auth_type: serviceAccount
+  prometheus_simple:
+    rule: type == "pod"
+`), &config)
+	assert.NoError(t, err)
+
+	// test
+	rules := For(logger, "receivers", "receiver_creator/kubelet", config)
+
+	// verify
+	assert.Equal(t, For(logger, "receivers", "kubeletstats", nil), rules)
+}
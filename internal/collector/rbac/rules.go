@@ -0,0 +1,136 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rbac is for determining the permissions the collector components need based on their configuration.
+package rbac
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// Builder specifies the signature required for the rule builders: it returns the rules needed by the named component
+// given its configuration.
+type Builder func(logr.Logger, string, map[interface{}]interface{}) []rbacv1.PolicyRule
+
+// registry holds a record of all known rule builders, by section and component type.
+var registry = make(map[string]map[string]Builder)
+
+// namespacedRegistry holds the builders of the rules granted in the namespace of the collector only, by section and
+// component type.
+var namespacedRegistry = make(map[string]map[string]Builder)
+
+var readOnly = []string{"get", "list", "watch"}
+
+// Register adds a new rule builder for the components of the given type in a config section, like "receivers".
+func Register(section, componentType string, builder Builder) {
+	if registry[section] == nil {
+		registry[section] = make(map[string]Builder)
+	}
+	registry[section][componentType] = builder
+}
+
+// RegisterNamespaced adds a new builder for the rules the components of the given type in a config section need in the
+// namespace of the collector only, like the config map some receivers elect a leader with.
+func RegisterNamespaced(section, componentType string, builder Builder) {
+	if namespacedRegistry[section] == nil {
+		namespacedRegistry[section] = make(map[string]Builder)
+	}
+	namespacedRegistry[section][componentType] = builder
+}
+
+// IsRegistered checks whether a rule builder is registered for the components of the given type in a config section.
+func IsRegistered(section, componentType string) bool {
+	_, ok := registry[section][componentType]
+	return ok
+}
+
+// For returns the rules needed by the named component of a config section. Components without a registered builder
+// don't talk to the Kubernetes API and need no rules.
+func For(logger logr.Logger, section, name string, config map[interface{}]interface{}) []rbacv1.PolicyRule {
+	builder := registry[section][componentType(name)]
+	if builder == nil {
+		return nil
+	}
+
+	return builder(logger, name, config)
+}
+
+// NamespacedFor returns the rules needed by the named component of a config section in the namespace of the collector.
+func NamespacedFor(logger logr.Logger, section, name string, config map[interface{}]interface{}) []rbacv1.PolicyRule {
+	builder := namespacedRegistry[section][componentType(name)]
+	if builder == nil {
+		return nil
+	}
+
+	return builder(logger, name, config)
+}
+
+// Merge combines rules granting verbs on the same API group into a single rule, so that the resulting list is short
+// and stable regardless of the order the components were declared in.
+func Merge(rules []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	merged := map[string]*rbacv1.PolicyRule{}
+	var keys []string
+	for _, rule := range rules {
+		verbs := sortedSet(rule.Verbs)
+		names := sortedSet(rule.ResourceNames)
+		for _, group := range rule.APIGroups {
+			key := strings.Join([]string{group, strings.Join(verbs, ","), strings.Join(names, ",")}, " ")
+			if merged[key] == nil {
+				merged[key] = &rbacv1.PolicyRule{APIGroups: []string{group}, Verbs: verbs, ResourceNames: names}
+				keys = append(keys, key)
+			}
+			merged[key].Resources = sortedSet(append(merged[key].Resources, rule.Resources...))
+		}
+	}
+	sort.Strings(keys)
+
+	res := make([]rbacv1.PolicyRule, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, *merged[key])
+	}
+	return res
+}
+
+func sortedSet(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var res []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			res = append(res, v)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func componentType(name string) string {
+	// components have a name like:
+	// - k8s_observer/custom
+	// - k8s_observer
+	// we extract the "k8s_observer" part and see if we have a builder for the component
+	if i := strings.Index(name, "/"); i >= 0 {
+		return name[:i]
+	}
+
+	return name
+}
@@ -0,0 +1,83 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var logger = logf.Log.WithName("unit-tests")
+
+func TestComponentType(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		name     string
+		expected string
+	}{
+		{"regular case", "k8s_observer", "k8s_observer"},
+		{"named instance", "k8s_observer/custom", "k8s_observer"},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			// test and verify
+			assert.Equal(t, tt.expected, componentType(tt.name))
+		})
+	}
+}
+
+func TestRegisterBuilder(t *testing.T) {
+	// prepare
+	builder := func(logr.Logger, string, map[interface{}]interface{}) []rbacv1.PolicyRule {
+		return []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}}
+	}
+
+	// test
+	Register("receivers", "mock", builder)
+
+	// verify
+	assert.True(t, IsRegistered("receivers", "mock"))
+	assert.False(t, IsRegistered("processors", "mock"))
+	assert.Len(t, For(logger, "receivers", "mock/custom", map[interface{}]interface{}{}), 1)
+}
+
+func TestUnknownComponentNeedsNoRules(t *testing.T) {
+	assert.Nil(t, For(logger, "receivers", "otlp", map[interface{}]interface{}{}))
+	assert.Nil(t, For(logger, "unknown", "k8s_cluster", map[interface{}]interface{}{}))
+}
+
+func TestMerge(t *testing.T) {
+	// prepare
+	rules := []rbacv1.PolicyRule{
+		{APIGroups: []string{"apps"}, Resources: []string{"replicasets"}, Verbs: []string{"watch", "get", "list"}},
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: readOnly},
+		{APIGroups: []string{""}, Resources: []string{"nodes/stats"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"namespaces", "pods"}, Verbs: readOnly},
+		{APIGroups: []string{"apps"}, Resources: []string{"replicasets"}, Verbs: readOnly},
+	}
+
+	// test
+	merged := Merge(rules)
+
+	// verify
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"nodes/stats"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"namespaces", "pods"}, Verbs: readOnly},
+		{APIGroups: []string{"apps"}, Resources: []string{"replicasets"}, Verbs: readOnly},
+	}, merged)
+}
@@ -0,0 +1,148 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+	. "github.com/signalfx/splunk-otel-collector-operator/internal/collector"
+)
+
+func TestClusterRoleRulesForDefaultConfigs(t *testing.T) {
+	// prepare
+	otelcol := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "my-ns",
+		},
+	}
+	otelcol.Default()
+	resources := func(component string) []string {
+		var res []string
+		for _, rule := range ClusterRole(logger, otelcol, component).Rules {
+			res = append(res, rule.Resources...)
+		}
+		return res
+	}
+
+	// test
+	gateway := ClusterRole(logger, otelcol, "gateway")
+
+	// verify
+	assert.Equal(t, "my-ns.my-instance-gateway", gateway.Name)
+	assert.Equal(t, "my-ns.my-instance", gateway.Labels["app.kubernetes.io/instance"])
+	assert.NotContains(t, resources("gateway"), "nodes")
+	assert.NotContains(t, resources("gateway"), "nodes/stats")
+	assert.Contains(t, resources("agent"), "nodes/stats")
+	assert.Contains(t, resources("cluster-receiver"), "events")
+	assert.NotContains(t, resources("agent"), "events")
+	assert.NotContains(t, resources("gateway"), "events")
+	assert.Contains(t, resources("agent"), "securitycontextconstraints")
+	for _, component := range v1alpha1.Components {
+		assert.Contains(t, ClusterRole(logger, otelcol, component).Rules,
+			rbacv1.PolicyRule{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}})
+	}
+}
+
+func TestClusterRoleFollowsConfig(t *testing.T) {
+	// prepare
+	otelcol := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "my-ns",
+		},
+		Spec: v1alpha1.AgentSpec{Gateway: v1alpha1.CollectorSpec{
+			Config: "receivers:\n  otlp:\n",
+			ConfigOverlay: `receivers:
+  k8s_events:
+  k8sobjects:
+    objects:
+      - name: events
+        group: events.k8s.io
+        mode: watch
+`,
+		}},
+	}
+
+	// test
+	role := ClusterRole(logger, otelcol, "gateway")
+
+	// verify
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"events", "namespaces"}, Verbs: []string{"get", "list", "watch"}},
+		{APIGroups: []string{"events.k8s.io"}, Resources: []string{"events"}, Verbs: []string{"get", "list", "watch"}},
+	}, role.Rules)
+}
+
+func TestClusterRoleBindingSubject(t *testing.T) {
+	// prepare
+	otelcol := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "my-ns",
+		},
+		Spec: v1alpha1.AgentSpec{Gateway: v1alpha1.CollectorSpec{
+			ServiceAccount: "my-special-sa",
+		}},
+	}
+
+	// test
+	agent := ClusterRoleBinding(otelcol, "agent")
+	gateway := ClusterRoleBinding(otelcol, "gateway")
+
+	// verify
+	assert.Equal(t, "my-ns.my-instance-agent", agent.RoleRef.Name)
+	assert.Equal(t, "my-instance-agent", agent.Subjects[0].Name)
+	assert.Equal(t, "my-ns", agent.Subjects[0].Namespace)
+	assert.Equal(t, "my-special-sa", gateway.Subjects[0].Name)
+}
+
+func TestRoleFollowsConfig(t *testing.T) {
+	// prepare
+	otelcol := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "my-ns",
+		},
+		Spec: v1alpha1.AgentSpec{ClusterReceiver: v1alpha1.CollectorSpec{
+			Config: `receivers:
+  smartagent/cluster:
+    type: kubernetes-cluster
+`,
+		}},
+	}
+
+	// test
+	role := Role(logger, otelcol, "cluster-receiver")
+	binding := RoleBinding(otelcol, "cluster-receiver")
+
+	// verify
+	leaderElection := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"create", "get", "update"}}
+	assert.Equal(t, "my-instance-cluster-receiver", role.Name)
+	assert.Equal(t, "my-ns", role.Namespace)
+	assert.Equal(t, []rbacv1.PolicyRule{leaderElection}, role.Rules)
+	assert.NotContains(t, ClusterRole(logger, otelcol, "cluster-receiver").Rules, leaderElection)
+	assert.Empty(t, Role(logger, otelcol, "agent").Rules)
+
+	assert.Equal(t, rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "my-instance-cluster-receiver"}, binding.RoleRef)
+	assert.Equal(t, "my-instance-cluster-receiver", binding.Subjects[0].Name)
+	assert.Equal(t, "my-ns", binding.Subjects[0].Namespace)
+}
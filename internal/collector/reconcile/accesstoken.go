@@ -0,0 +1,90 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
+)
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+// AccessTokens syncs the access token of the instance from the secret referenced by its spec into the secret read by
+// the collectors. The synced secret is kept when the source goes missing, so that running collectors keep working.
+func AccessTokens(ctx context.Context, params Params) error {
+	desired, kept := []*corev1.Secret{}, []*corev1.Secret{}
+	if source, key, ok := collector.AccessTokenSource(params.Instance); ok {
+		token, err := sourceAccessToken(ctx, params, source, key)
+		if err != nil {
+			return err
+		}
+
+		if len(token) > 0 {
+			secret := collector.AccessTokenSecret(params.Instance, token)
+			desired = append(desired, &secret)
+		} else {
+			params.Log.V(2).Info("access token source not found", "secret", source, "key", key)
+			secret := collector.AccessTokenSecret(params.Instance, nil)
+			kept = append(kept, &secret)
+		}
+	}
+
+	// first, handle the create/update parts
+	if err := applyObjects(ctx, params, desired); err != nil {
+		return fmt.Errorf("failed to reconcile the expected access token secrets: %w", err)
+	}
+
+	// then, delete the extra objects
+	if err := pruneObjects(ctx, params, &corev1.SecretList{}, append(desired, kept...), client.InNamespace(params.Instance.Namespace), instanceLabels(params, nil)); err != nil {
+		return fmt.Errorf("failed to reconcile the access token secrets to be deleted: %w", err)
+	}
+
+	return nil
+}
+
+// sourceAccessToken returns the token held by the given key of the source secret, nil when either is missing.
+func sourceAccessToken(ctx context.Context, params Params, source types.NamespacedName, key string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := params.Client.Get(ctx, source, secret); k8serrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get the access token source %s: %w", source, err)
+	}
+	return secret.Data[key], nil
+}
+
+// accessTokenPending tells whether the collectors of the instance wait for the access token synced by the operator.
+func accessTokenPending(ctx context.Context, params Params) (bool, error) {
+	if _, _, ok := collector.AccessTokenSource(params.Instance); !ok {
+		return false, nil
+	}
+
+	secret := &corev1.Secret{}
+	err := params.Client.Get(ctx, types.NamespacedName{Namespace: params.Instance.Namespace, Name: v1alpha1.AccessTokenSecret}, secret)
+	if k8serrors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get the access token secret: %w", err)
+	}
+	return len(secret.Data[v1alpha1.AccessTokenSecretKey]) == 0, nil
+}
@@ -0,0 +1,99 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+)
+
+func TestAccessTokens(t *testing.T) {
+	param := params()
+	param.Instance.Spec.AccessToken = &v1alpha1.AccessTokenSource{
+		SecretRef: v1alpha1.SecretKeyReference{Name: "test-token-source", Key: "token"},
+	}
+	synced := types.NamespacedName{Namespace: "default", Name: "splunk-access-token"}
+	source := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-token-source", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("my-token")},
+	}
+	defer func() {
+		_ = k8sClient.Delete(context.Background(), source)
+		_ = k8sClient.Delete(context.Background(), &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: synced.Name, Namespace: synced.Namespace}})
+	}()
+
+	t.Run("should wait for the source", func(t *testing.T) {
+		require.NoError(t, AccessTokens(context.Background(), param))
+
+		exists, err := populateObjectIfExists(t, &v1.Secret{}, synced)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+
+		pending, err := accessTokenPending(context.Background(), param)
+		assert.NoError(t, err)
+		assert.True(t, pending)
+
+		condition, err := accessTokenMissingCondition(context.Background(), param)
+		assert.NoError(t, err)
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Equal(t, `waiting for the key "token" of the secret "default/test-token-source"`, condition.Message)
+	})
+
+	t.Run("should sync the token", func(t *testing.T) {
+		require.NoError(t, k8sClient.Create(context.Background(), source))
+		require.NoError(t, AccessTokens(context.Background(), param))
+
+		actual := &v1.Secret{}
+		exists, err := populateObjectIfExists(t, actual, synced)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, []byte("my-token"), actual.Data["access-token"])
+
+		pending, err := accessTokenPending(context.Background(), param)
+		assert.NoError(t, err)
+		assert.False(t, pending)
+	})
+
+	t.Run("should keep the token when the source is deleted", func(t *testing.T) {
+		require.NoError(t, k8sClient.Delete(context.Background(), source))
+		require.NoError(t, AccessTokens(context.Background(), param))
+
+		exists, err := populateObjectIfExists(t, &v1.Secret{}, synced)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("should delete the token when unmanaged", func(t *testing.T) {
+		unmanaged := params()
+		require.NoError(t, AccessTokens(context.Background(), unmanaged))
+
+		exists, err := populateObjectIfExists(t, &v1.Secret{}, synced)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+
+		condition, err := accessTokenMissingCondition(context.Background(), unmanaged)
+		assert.NoError(t, err)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, "Unmanaged", condition.Reason)
+	})
+}
@@ -20,10 +20,7 @@ import (
 	"fmt"
 
 	appsv1 "k8s.io/api/apps/v1"
-	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/naming"
@@ -33,116 +30,44 @@ import (
 
 // Agents reconciles the Splunk Otel Agent required for the instance in the current context.
 func Agents(ctx context.Context, params Params) error {
-	desired := []appsv1.DaemonSet{}
-	if params.Instance.Spec.Agent.Enabled == nil || *params.Instance.Spec.Agent.Enabled {
-		// TODO(splunk): pass params.Instance.Spec.Agent instead of params.Instance
-		desired = append(desired, collector.Agent(params.Log, params.Instance))
+	// the workload is left as is, including when the component gets disabled
+	if params.Instance.ComponentPaused("agent") {
+		params.Log.V(2).Info("reconciliation paused", "component", "agent")
+		return nil
 	}
 
-	// first, handle the create/update parts
-	if err := expectedAgents(ctx, params, desired); err != nil {
-		return fmt.Errorf("failed to reconcile the expected daemon sets: %w", err)
+	// the collectors would crash-loop until the access token synced by the operator is available
+	pending, err := accessTokenPending(ctx, params)
+	if err != nil {
+		return err
 	}
-
-	// then, delete the extra objects
-	if err := deleteAgents(ctx, params, desired); err != nil {
-		return fmt.Errorf("failed to reconcile the daemon sets to be deleted: %w", err)
+	if pending {
+		params.Log.V(2).Info("waiting for the access token", "component", "agent")
+		return nil
 	}
 
-	return nil
-}
-
-func expectedAgents(ctx context.Context, params Params, expected []appsv1.DaemonSet) error {
-	for _, obj := range expected {
-		desired := obj
-
-		if err := controllerutil.SetControllerReference(&params.Instance, &desired, params.Scheme); err != nil {
-			return fmt.Errorf("failed to set controller reference: %w", err)
-		}
-
-		existing := &appsv1.DaemonSet{}
-		nns := types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}
-
-		err := params.Client.Get(ctx, nns, existing)
-		if err != nil && k8serrors.IsNotFound(err) {
-			if err = params.Client.Create(ctx, &desired); err != nil {
-				return fmt.Errorf("failed to create: %w", err)
-			}
-			params.Log.V(2).Info("created", "daemonset.name", desired.Name, "daemonset.namespace", desired.Namespace)
-			continue
-		} else if err != nil {
-			return fmt.Errorf("failed to get: %w", err)
-		}
-
-		if err := params.Client.Get(ctx, nns, existing); err != nil && k8serrors.IsNotFound(err) {
-			if err = params.Client.Create(ctx, &desired); err != nil {
-				return fmt.Errorf("failed to create: %w", err)
-			}
-			params.Log.V(2).Info("created", "daemonset.name", desired.Name, "daemonset.namespace", desired.Namespace)
-			continue
-		} else if err != nil {
-			return fmt.Errorf("failed to get: %w", err)
-		}
-
-		// it exists already, merge the two if the end result isn't identical to the existing one
-		updated := existing.DeepCopy()
-		if updated.Annotations == nil {
-			updated.Annotations = map[string]string{}
-		}
-		if updated.Labels == nil {
-			updated.Labels = map[string]string{}
-		}
-
-		updated.Spec = desired.Spec
-		updated.ObjectMeta.OwnerReferences = desired.ObjectMeta.OwnerReferences
-
-		for k, v := range desired.ObjectMeta.Annotations {
-			updated.ObjectMeta.Annotations[k] = v
-		}
-		for k, v := range desired.ObjectMeta.Labels {
-			updated.ObjectMeta.Labels[k] = v
-		}
-
-		patch := client.MergeFrom(existing)
-		if err := params.Client.Patch(ctx, updated, patch); err != nil {
-			return fmt.Errorf("failed to apply changes: %w", err)
+	desired := []*appsv1.DaemonSet{}
+	if params.Instance.Spec.Agent.Enabled == nil || *params.Instance.Spec.Agent.Enabled {
+		// TODO(splunk): pass params.Instance.Spec.Agent instead of params.Instance
+		obj := collector.Agent(params.Log, params.Instance)
+		if err := withConfigHash(ctx, params, "agent", &obj.Spec.Template); err != nil {
+			return fmt.Errorf("failed to hash the agent configuration: %w", err)
 		}
-
-		params.Log.V(2).Info("applied", "daemonset.name", desired.Name, "daemonset.namespace", desired.Namespace)
+		desired = append(desired, &obj)
 	}
 
-	return nil
-}
-
-func deleteAgents(ctx context.Context, params Params, expected []appsv1.DaemonSet) error {
-	opts := []client.ListOption{
-		client.InNamespace(params.Instance.Namespace),
-		client.MatchingLabels(map[string]string{
-			"app.kubernetes.io/instance":   fmt.Sprintf("%s.%s", params.Instance.Namespace, params.Instance.Name),
-			"app.kubernetes.io/managed-by": "splunk-otel-collector-operator",
-			"app.kubernetes.io/name":       naming.Agent(params.Instance),
-		}),
+	// first, handle the create/update parts
+	if err := applyObjects(ctx, params, desired); err != nil {
+		return fmt.Errorf("failed to reconcile the expected daemon sets: %w", err)
 	}
-	list := &appsv1.DaemonSetList{}
-	if err := params.Client.List(ctx, list, opts...); err != nil {
-		return fmt.Errorf("failed to list: %w", err)
+	if err := removeWorkloadConfigHash(ctx, params, desired); err != nil {
+		return fmt.Errorf("failed to reconcile the expected daemon sets: %w", err)
 	}
 
-	for i := range list.Items {
-		existing := list.Items[i]
-		del := true
-		for _, keep := range expected {
-			if keep.Name == existing.Name && keep.Namespace == existing.Namespace {
-				del = false
-			}
-		}
-
-		if del {
-			if err := params.Client.Delete(ctx, &existing); err != nil {
-				return fmt.Errorf("failed to delete: %w", err)
-			}
-			params.Log.V(2).Info("deleted", "daemonset.name", existing.Name, "daemonset.namespace", existing.Namespace)
-		}
+	// then, delete the extra objects
+	selector := instanceLabels(params, map[string]string{"app.kubernetes.io/name": naming.Agent(params.Instance)})
+	if err := pruneObjects(ctx, params, &appsv1.DaemonSetList{}, desired, client.InNamespace(params.Instance.Namespace), selector); err != nil {
+		return fmt.Errorf("failed to reconcile the daemon sets to be deleted: %w", err)
 	}
 
 	return nil
@@ -24,6 +24,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/naming"
@@ -34,7 +35,7 @@ func TestExpectedDaemonsets(t *testing.T) {
 	expectedDs := collector.Agent(logger, param.Instance)
 
 	t.Run("should create Daemonset", func(t *testing.T) {
-		err := expectedAgents(context.Background(), param, []v1.DaemonSet{expectedDs})
+		err := applyObjects(context.Background(), param, []*v1.DaemonSet{&expectedDs})
 		assert.NoError(t, err)
 
 		exists, err := populateObjectIfExists(t, &v1.DaemonSet{}, types.NamespacedName{Namespace: "default", Name: "test-agent"})
@@ -45,7 +46,7 @@ func TestExpectedDaemonsets(t *testing.T) {
 	})
 	t.Run("should update Daemonset", func(t *testing.T) {
 		createObjectIfNotExists(t, "test-agent", &expectedDs)
-		err := expectedAgents(context.Background(), param, []v1.DaemonSet{expectedDs})
+		err := applyObjects(context.Background(), param, []*v1.DaemonSet{&expectedDs})
 		assert.NoError(t, err)
 
 		actual := v1.DaemonSet{}
@@ -86,7 +87,7 @@ func TestExpectedDaemonsets(t *testing.T) {
 
 		createObjectIfNotExists(t, "dummy-cluster-receiver", &ds)
 
-		err := deleteAgents(context.Background(), param, []v1.DaemonSet{expectedDs})
+		err := pruneObjects(context.Background(), param, &v1.DaemonSetList{}, []*v1.DaemonSet{&expectedDs}, client.InNamespace("default"), instanceLabels(param, map[string]string{"app.kubernetes.io/name": naming.Agent(param.Instance)}))
 		assert.NoError(t, err)
 
 		actual := v1.DaemonSet{}
@@ -126,7 +127,7 @@ func TestExpectedDaemonsets(t *testing.T) {
 
 		createObjectIfNotExists(t, "dummy-cluster-receiver", &ds)
 
-		err := deleteAgents(context.Background(), param, []v1.DaemonSet{expectedDs})
+		err := pruneObjects(context.Background(), param, &v1.DaemonSetList{}, []*v1.DaemonSet{&expectedDs}, client.InNamespace("default"), instanceLabels(param, map[string]string{"app.kubernetes.io/name": naming.Agent(param.Instance)}))
 		assert.NoError(t, err)
 
 		actual := v1.DaemonSet{}
@@ -136,3 +137,18 @@ func TestExpectedDaemonsets(t *testing.T) {
 
 	})
 }
+
+func TestPausedAgents(t *testing.T) {
+	t.Run("should neither create nor delete the daemonset of a paused agent", func(t *testing.T) {
+		param := params()
+		param.Instance.Name = "test-paused"
+		param.Instance.Annotations = map[string]string{"otel.splunk.com/reconcile-agent": "paused"}
+
+		err := Agents(context.Background(), param)
+		assert.NoError(t, err)
+
+		exists, err := populateObjectIfExists(t, &v1.DaemonSet{}, types.NamespacedName{Namespace: "default", Name: "test-paused-agent"})
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
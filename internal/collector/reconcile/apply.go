@@ -0,0 +1,334 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+)
+
+// FieldManager is the field manager the operator applies the objects it manages with. Fields the operator doesn't set
+// are left to their own managers, like the annotations added by other controllers, or the replicas of a gateway that
+// doesn't set them or is scaled by an HPA: the gateway is applied without replicas then.
+const FieldManager = "splunk-otel-collector-operator"
+
+// legacyFieldManager is the field manager of the updates the operator made to the objects it manages before it applied
+// them. Clients without a field manager are named after their binary, the operator binary is "manager", like the
+// binaries of many other controllers: the updates are told apart by the fields and the time they were made instead.
+const legacyFieldManager = "manager"
+
+// managedByPath is the label the operator always set on the objects it created and updated.
+var managedByPath = fieldpath.MakePathOrDie("metadata", "labels", "app.kubernetes.io/managed-by")
+
+// applyObjects creates or updates the desired objects with server-side apply, taking over the fields they set from
+// any other manager. Namespaced objects are owned by the instance in the current context, cluster-scoped objects can't
+// be and are matched with their instance by labels instead: existing ones labeled for another instance are refused.
+// The desired objects are updated with the applied state.
+//
+// The fields the operator set with updates before it applied the objects are handed over to its field manager first,
+// so that they're removed once the operator doesn't set them anymore.
+//
+// Objects already applied from the same desired state are only applied again when they drifted: the fields set by the
+// operator were changed by someone else since. Drifted objects are reported, and applied anyway unless the drift policy
+// of the instance is report-only. Objects that aren't applied are updated with their live state instead.
+func applyObjects[T client.Object](ctx context.Context, params Params, desired []T) error {
+	for _, obj := range desired {
+		if obj.GetNamespace() != "" {
+			if err := controllerutil.SetControllerReference(&params.Instance, obj, params.Scheme); err != nil {
+				return fmt.Errorf("failed to set controller reference: %w", err)
+			}
+		}
+
+		// apply patches are sent as is, they need the kind of the object and mustn't carry server-side metadata
+		gvk, err := apiutil.GVKForObject(obj, params.Scheme)
+		if err != nil {
+			return fmt.Errorf("failed to get the kind of %q: %w", obj.GetName(), err)
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+		obj.SetResourceVersion("")
+		obj.SetManagedFields(nil)
+
+		hash, err := appliedHash(obj)
+		if err != nil {
+			return fmt.Errorf("failed to hash %q: %w", obj.GetName(), err)
+		}
+
+		live, err := getLive(ctx, params, gvk, obj)
+		if err != nil {
+			return fmt.Errorf("failed to get %s %q: %w", gvk.Kind, obj.GetName(), err)
+		}
+		if live != nil && live.GetNamespace() == "" && !managedByInstance(params, live) {
+			return fmt.Errorf("%s %q already exists and isn't managed by this instance", gvk.Kind, obj.GetName())
+		}
+		if live != nil {
+			if err := migrateManagedFields(ctx, params, live); err != nil {
+				return fmt.Errorf("failed to migrate the managed fields of %s %q: %w", gvk.Kind, obj.GetName(), err)
+			}
+		}
+
+		drifted, err := detectDrift(ctx, params, obj, live, hash)
+		if err != nil {
+			return fmt.Errorf("failed to detect the drift of %s %q: %w", gvk.Kind, obj.GetName(), err)
+		}
+		if len(drifted) > 0 {
+			reportDrift(params, gvk.Kind, obj, drifted)
+			if params.Instance.Spec.DriftPolicy == v1alpha1.DriftPolicyReportOnly {
+				// the object is left as is, as if it had been applied
+				reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(live).Elem())
+				continue
+			}
+		} else if live != nil && live.GetAnnotations()[AppliedHashAnnotation] == hash {
+			// applying the object again wouldn't change it
+			reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(live).Elem())
+			continue
+		}
+
+		if err := params.Client.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership); err != nil {
+			return fmt.Errorf("failed to apply %s %q: %w", gvk.Kind, obj.GetName(), err)
+		}
+		instanceVerifiedVersions(params).Store(obj.GetUID(), obj.GetResourceVersion())
+
+		params.Log.V(2).Info("applied", "kind", gvk.Kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+	}
+
+	return nil
+}
+
+// getLive returns the live state of the desired object, nil when it doesn't exist.
+func getLive(ctx context.Context, params Params, gvk schema.GroupVersionKind, desired client.Object) (client.Object, error) {
+	obj, err := params.Scheme.New(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a %s: %w", gvk.Kind, err)
+	}
+	live := obj.(client.Object)
+	if err := params.Client.Get(ctx, client.ObjectKeyFromObject(desired), live); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return live, nil
+}
+
+// migrateManagedFields hands the fields owned by the legacy field manager of the live object over to FieldManager,
+// the way kubectl upgrades objects from client-side to server-side apply. The live object is updated with the result.
+func migrateManagedFields(ctx context.Context, params Params, live client.Object) error {
+	entries, changed, err := migratedManagedFields(live.GetManagedFields())
+	if err != nil || !changed {
+		return err
+	}
+
+	// the resource version is tested so that changes made since the object was read aren't lost
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": live.GetResourceVersion()},
+		{"op": "replace", "path": "/metadata/managedFields", "value": entries},
+	})
+	if err != nil {
+		return err
+	}
+	if err := params.Client.Patch(ctx, live, client.RawPatch(types.JSONPatchType, patch)); err != nil {
+		return err
+	}
+
+	params.Log.V(2).Info("migrated managed fields", "name", live.GetName(), "namespace", live.GetNamespace())
+	return nil
+}
+
+// migratedManagedFields merges the fields of the updates the operator made before it applied the object into the
+// fields applied by FieldManager. It reports whether there was anything to migrate.
+func migratedManagedFields(entries []metav1.ManagedFieldsEntry) ([]metav1.ManagedFieldsEntry, bool, error) {
+	var appliedAt *metav1.Time
+	for _, entry := range entries {
+		if entry.Subresource == "" && entry.Operation == metav1.ManagedFieldsOperationApply && entry.Manager == FieldManager {
+			appliedAt = entry.Time
+		}
+	}
+
+	var res []metav1.ManagedFieldsEntry
+	var apiVersion string
+	legacy := &fieldpath.Set{}
+	migrated, applied := false, -1
+	for _, entry := range entries {
+		set, err := legacyFieldSet(entry, appliedAt)
+		if err != nil {
+			return nil, false, err
+		}
+		if set != nil {
+			legacy = legacy.Union(set)
+			apiVersion = entry.APIVersion
+			migrated = true
+			continue
+		}
+		if entry.Subresource == "" && entry.Operation == metav1.ManagedFieldsOperationApply && entry.Manager == FieldManager {
+			applied = len(res)
+		}
+		res = append(res, entry)
+	}
+	if !migrated {
+		return entries, false, nil
+	}
+
+	if applied < 0 {
+		res = append(res, metav1.ManagedFieldsEntry{
+			Manager:    FieldManager,
+			Operation:  metav1.ManagedFieldsOperationApply,
+			APIVersion: apiVersion,
+			Time:       &metav1.Time{Time: metav1.Now().Rfc3339Copy().Time},
+			FieldsType: "FieldsV1",
+		})
+		applied = len(res) - 1
+	}
+	set, err := fieldSet(res[applied])
+	if err != nil {
+		return nil, false, err
+	}
+	raw, err := set.Union(legacy).ToJSON()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to serialize the managed fields: %w", err)
+	}
+	res[applied].FieldsV1 = &metav1.FieldsV1{Raw: raw}
+	return res, true, nil
+}
+
+// legacyFieldSet returns the fields of the entry when it's an update made by the operator before it applied the
+// object, nil otherwise. Those updates own the managed-by label of the operator, and can't be more recent than the
+// fields applied by FieldManager, at the given time if any: the migrated entries are gone after the first apply.
+func legacyFieldSet(entry metav1.ManagedFieldsEntry, appliedAt *metav1.Time) (*fieldpath.Set, error) {
+	if entry.Subresource != "" || entry.Operation != metav1.ManagedFieldsOperationUpdate || entry.Manager != legacyFieldManager {
+		return nil, nil
+	}
+	if appliedAt != nil && entry.Time != nil && entry.Time.After(appliedAt.Time) {
+		return nil, nil
+	}
+
+	set, err := fieldSet(entry)
+	if err != nil {
+		return nil, err
+	}
+	if !set.Has(managedByPath) {
+		return nil, nil
+	}
+	return set, nil
+}
+
+func fieldSet(entry metav1.ManagedFieldsEntry) (*fieldpath.Set, error) {
+	set := &fieldpath.Set{}
+	if entry.FieldsV1 == nil || len(entry.FieldsV1.Raw) == 0 {
+		return set, nil
+	}
+	if err := set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+		return nil, fmt.Errorf("failed to parse the fields managed by %q: %w", entry.Manager, err)
+	}
+	return set, nil
+}
+
+// pruneObjects deletes the objects selected by the list options that aren't desired anymore. The options should
+// select by instanceLabels, so that objects managed by other instances or tools are left alone.
+func pruneObjects[T client.Object](ctx context.Context, params Params, list client.ObjectList, desired []T, opts ...client.ListOption) error {
+	if err := params.Client.List(ctx, list, opts...); err != nil {
+		return fmt.Errorf("failed to list: %w", err)
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return fmt.Errorf("failed to extract the listed objects: %w", err)
+	}
+
+	for _, item := range items {
+		existing, ok := item.(client.Object)
+		if !ok || isDesired(existing, desired) {
+			continue
+		}
+
+		if err := params.Client.Delete(ctx, existing); err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %q: %w", existing.GetName(), err)
+		}
+		instanceVerifiedVersions(params).Delete(existing.GetUID())
+		params.Log.V(2).Info("deleted", "kind", fmt.Sprintf("%T", existing), "name", existing.GetName(), "namespace", existing.GetNamespace())
+	}
+
+	return nil
+}
+
+// appliedHash returns the hash of the desired object, stamped on it as the AppliedHashAnnotation.
+func appliedHash(obj client.Object) (string, error) {
+	// the annotations of the desired objects are often shared with the instance, they're copied before being changed
+	annotations := map[string]string{}
+	for k, v := range obj.GetAnnotations() {
+		if k != AppliedHashAnnotation {
+			annotations[k] = v
+		}
+	}
+	obj.SetAnnotations(annotations)
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256(data))
+
+	annotations[AppliedHashAnnotation] = hash
+	return hash, nil
+}
+
+func isDesired[T client.Object](existing client.Object, desired []T) bool {
+	for _, keep := range desired {
+		if keep.GetName() == existing.GetName() && keep.GetNamespace() == existing.GetNamespace() {
+			return true
+		}
+	}
+	return false
+}
+
+// managedByInstance reports whether the live object is labeled for the instance in the current context. Cluster-scoped
+// objects can't be owned by the instance, an object of the same name managed by another instance or tool mustn't be
+// taken over.
+func managedByInstance(params Params, live client.Object) bool {
+	for k, v := range instanceLabels(params, nil) {
+		if live.GetLabels()[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// instanceLabels selects the objects managed for the instance in the current context. Extra labels narrow the
+// selection down, like the name of a component sharing its kind of workload with others.
+func instanceLabels(params Params, extra map[string]string) client.MatchingLabels {
+	labels := map[string]string{
+		"app.kubernetes.io/instance":   fmt.Sprintf("%s.%s", params.Instance.Namespace, params.Instance.Name),
+		"app.kubernetes.io/managed-by": "splunk-otel-collector-operator",
+	}
+	for k, v := range extra {
+		labels[k] = v
+	}
+	return client.MatchingLabels(labels)
+}
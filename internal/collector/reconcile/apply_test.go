@@ -0,0 +1,263 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestApplyObjects(t *testing.T) {
+	t.Run("should keep the fields set by other managers", func(t *testing.T) {
+		p := params()
+		desired := desiredConfigMap(context.Background(), p, p.Instance.Spec.Agent.Config, "apply")
+		require.NoError(t, applyObjects(context.Background(), p, []*v1.ConfigMap{&desired}))
+
+		// another controller annotates the config map
+		existing := &v1.ConfigMap{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(&desired), existing))
+		patch := client.MergeFrom(existing.DeepCopy())
+		existing.Annotations = map[string]string{"example.com/owner": "someone-else"}
+		require.NoError(t, k8sClient.Patch(context.Background(), existing, patch, client.FieldOwner("someone-else")))
+
+		desired = desiredConfigMap(context.Background(), p, "receivers:", "apply")
+		require.NoError(t, applyObjects(context.Background(), p, []*v1.ConfigMap{&desired}))
+
+		actual := v1.ConfigMap{}
+		exists, err := populateObjectIfExists(t, &actual, types.NamespacedName{Namespace: "default", Name: desired.Name})
+		require.NoError(t, err)
+		require.True(t, exists)
+		assert.Equal(t, "someone-else", actual.Annotations["example.com/owner"])
+		assert.Equal(t, "receivers:", actual.Data["collector.yaml"])
+		assert.Equal(t, instanceUID, actual.OwnerReferences[0].UID)
+
+		var managers []string
+		for _, entry := range actual.ManagedFields {
+			managers = append(managers, entry.Manager)
+		}
+		assert.Contains(t, managers, FieldManager)
+	})
+
+	t.Run("should not apply unchanged objects again", func(t *testing.T) {
+		p := params()
+		desired := desiredConfigMap(context.Background(), p, p.Instance.Spec.Agent.Config, "unchanged")
+		require.NoError(t, applyObjects(context.Background(), p, []*v1.ConfigMap{&desired}))
+		applied := desired.ResourceVersion
+
+		desired = desiredConfigMap(context.Background(), p, p.Instance.Spec.Agent.Config, "unchanged")
+		require.NoError(t, applyObjects(context.Background(), p, []*v1.ConfigMap{&desired}))
+
+		actual := v1.ConfigMap{}
+		exists, err := populateObjectIfExists(t, &actual, types.NamespacedName{Namespace: "default", Name: desired.Name})
+		require.NoError(t, err)
+		require.True(t, exists)
+		assert.Equal(t, applied, actual.ResourceVersion)
+		assert.Equal(t, applied, desired.ResourceVersion)
+	})
+
+	t.Run("should not take over cluster-scoped objects of another instance", func(t *testing.T) {
+		// prepare
+		p := params()
+		other := rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "apply-other-instance",
+				Labels: map[string]string{
+					"app.kubernetes.io/instance":   "other.test",
+					"app.kubernetes.io/managed-by": "splunk-otel-collector-operator",
+				},
+			},
+		}
+		createObjectIfNotExists(t, other.Name, &other)
+		desired := rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "apply-other-instance",
+				Labels: map[string]string{"app.kubernetes.io/instance": "default.test", "app.kubernetes.io/managed-by": "splunk-otel-collector-operator"},
+			},
+		}
+
+		// test
+		err := applyObjects(context.Background(), p, []*rbacv1.ClusterRole{&desired})
+
+		// verify
+		assert.ErrorContains(t, err, "isn't managed by this instance")
+		actual := rbacv1.ClusterRole{}
+		exists, err := populateObjectIfExists(t, &actual, types.NamespacedName{Name: other.Name})
+		require.NoError(t, err)
+		require.True(t, exists)
+		assert.Equal(t, "other.test", actual.Labels["app.kubernetes.io/instance"])
+	})
+}
+
+func TestApplyObjectsMigratesManagedFields(t *testing.T) {
+	p := params()
+	desired := desiredConfigMap(context.Background(), p, p.Instance.Spec.Agent.Config, "migrate")
+
+	// the operator used to create and update the objects without a field manager
+	legacy := desired.DeepCopy()
+	legacy.Data["legacy.yaml"] = "receivers:"
+	require.NoError(t, k8sClient.Create(context.Background(), legacy, client.FieldOwner("manager")))
+
+	require.NoError(t, applyObjects(context.Background(), p, []*v1.ConfigMap{&desired}))
+
+	actual := v1.ConfigMap{}
+	exists, err := populateObjectIfExists(t, &actual, types.NamespacedName{Namespace: "default", Name: desired.Name})
+	require.NoError(t, err)
+	require.True(t, exists)
+	assert.NotContains(t, actual.Data, "legacy.yaml")
+	for _, entry := range actual.ManagedFields {
+		assert.NotEqual(t, "manager", entry.Manager)
+	}
+}
+
+func TestMigratedManagedFields(t *testing.T) {
+	before := metav1.NewTime(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	after := metav1.NewTime(before.Add(time.Hour))
+	legacy := metav1.ManagedFieldsEntry{
+		Manager:    "manager",
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		APIVersion: "v1",
+		Time:       &before,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:legacy.yaml":{}},"f:metadata":{"f:labels":{"f:app.kubernetes.io/managed-by":{}}}}`)},
+	}
+	applied := metav1.ManagedFieldsEntry{
+		Manager:    FieldManager,
+		Operation:  metav1.ManagedFieldsOperationApply,
+		APIVersion: "v1",
+		Time:       &before,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:collector.yaml":{}}}`)},
+	}
+	other := metav1.ManagedFieldsEntry{
+		Manager:    "kubectl-edit",
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:example.com/owner":{}}}}`)},
+	}
+
+	t.Run("should merge the legacy fields into the applied ones", func(t *testing.T) {
+		entries, changed, err := migratedManagedFields([]metav1.ManagedFieldsEntry{legacy, applied, other})
+		require.NoError(t, err)
+		assert.True(t, changed)
+		require.Len(t, entries, 2)
+		assert.Equal(t, FieldManager, entries[0].Manager)
+		assert.JSONEq(t, `{"f:data":{"f:collector.yaml":{},"f:legacy.yaml":{}},"f:metadata":{"f:labels":{"f:app.kubernetes.io/managed-by":{}}}}`, string(entries[0].FieldsV1.Raw))
+		assert.Equal(t, other, entries[1])
+	})
+
+	t.Run("should apply the legacy fields when never applied", func(t *testing.T) {
+		entries, changed, err := migratedManagedFields([]metav1.ManagedFieldsEntry{legacy})
+		require.NoError(t, err)
+		assert.True(t, changed)
+		require.Len(t, entries, 1)
+		assert.Equal(t, FieldManager, entries[0].Manager)
+		assert.Equal(t, metav1.ManagedFieldsOperationApply, entries[0].Operation)
+		assert.JSONEq(t, string(legacy.FieldsV1.Raw), string(entries[0].FieldsV1.Raw))
+	})
+
+	t.Run("should leave the updates of other controllers named manager", func(t *testing.T) {
+		foreign := legacy
+		foreign.FieldsV1 = &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:legacy.yaml":{}}}`)}
+		entries, changed, err := migratedManagedFields([]metav1.ManagedFieldsEntry{foreign, applied})
+		require.NoError(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, []metav1.ManagedFieldsEntry{foreign, applied}, entries)
+	})
+
+	t.Run("should leave the updates made after the operator applied the object", func(t *testing.T) {
+		later := legacy
+		later.Time = &after
+		entries, changed, err := migratedManagedFields([]metav1.ManagedFieldsEntry{later, applied})
+		require.NoError(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, []metav1.ManagedFieldsEntry{later, applied}, entries)
+	})
+
+	t.Run("should leave migrated fields as they are", func(t *testing.T) {
+		entries, changed, err := migratedManagedFields([]metav1.ManagedFieldsEntry{applied, other})
+		require.NoError(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, []metav1.ManagedFieldsEntry{applied, other}, entries)
+	})
+}
+
+func TestPruneObjects(t *testing.T) {
+	t.Run("should only delete the undesired objects of the instance", func(t *testing.T) {
+		p := params()
+		keep := desiredConfigMap(context.Background(), p, "", "prune-keep")
+		extra := desiredConfigMap(context.Background(), p, "", "prune-extra")
+		unrelated := v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "prune-unrelated",
+				Namespace: "default",
+				Labels:    map[string]string{"app.kubernetes.io/instance": "default.test"},
+			},
+		}
+		for _, cm := range []*v1.ConfigMap{&keep, &extra, &unrelated} {
+			createObjectIfNotExists(t, cm.Name, cm)
+		}
+
+		err := pruneObjects(context.Background(), p, &v1.ConfigMapList{}, []*v1.ConfigMap{&keep}, client.InNamespace("default"), instanceLabels(p, nil))
+		require.NoError(t, err)
+
+		for name, expected := range map[string]bool{keep.Name: true, extra.Name: false, unrelated.Name: true} {
+			exists, err := populateObjectIfExists(t, &v1.ConfigMap{}, types.NamespacedName{Namespace: "default", Name: name})
+			assert.NoError(t, err)
+			assert.Equal(t, expected, exists, name)
+		}
+	})
+}
+
+func TestManagedByInstance(t *testing.T) {
+	p := params()
+	for _, tt := range []struct {
+		desc     string
+		labels   map[string]string
+		expected bool
+	}{
+		{"labeled for the instance", map[string]string{"app.kubernetes.io/instance": "default.test", "app.kubernetes.io/managed-by": "splunk-otel-collector-operator", "app.kubernetes.io/name": "test-agent"}, true},
+		{"labeled for another instance", map[string]string{"app.kubernetes.io/instance": "default-test.agent", "app.kubernetes.io/managed-by": "splunk-otel-collector-operator"}, false},
+		{"not managed by the operator", map[string]string{"app.kubernetes.io/instance": "default.test"}, false},
+		{"unlabeled", nil, false},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			obj := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "test", Labels: tt.labels}}
+			assert.Equal(t, tt.expected, managedByInstance(p, obj))
+		})
+	}
+}
+
+func TestInstanceLabels(t *testing.T) {
+	p := params()
+	assert.Equal(t, client.MatchingLabels{
+		"app.kubernetes.io/instance":   "default.test",
+		"app.kubernetes.io/managed-by": "splunk-otel-collector-operator",
+	}, instanceLabels(p, nil))
+	assert.Equal(t, client.MatchingLabels{
+		"app.kubernetes.io/instance":   "default.test",
+		"app.kubernetes.io/managed-by": "splunk-otel-collector-operator",
+		"app.kubernetes.io/name":       "test-agent",
+	}, instanceLabels(p, map[string]string{"app.kubernetes.io/name": "test-agent"}))
+}
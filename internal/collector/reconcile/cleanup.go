@@ -0,0 +1,102 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+)
+
+// Finalizer keeps a deleted instance around until the objects it can't own, like cluster-scoped objects, are deleted.
+const Finalizer = "otel.splunk.com/cleanup"
+
+// unownedLists returns empty lists of the kinds of objects created for an instance that can't be owned by it, and
+// are therefore not garbage collected along with it.
+func unownedLists() []client.ObjectList {
+	return []client.ObjectList{
+		&rbacv1.ClusterRoleBindingList{},
+		&rbacv1.ClusterRoleList{},
+	}
+}
+
+// CleanUp deletes the objects labeled for the deleted instance in the current context that can't be owned by it,
+// returning the ones that still exist, as "<kind> <name>". The cleanup is complete once none is returned.
+func CleanUp(ctx context.Context, params Params) ([]string, error) {
+	for _, list := range unownedLists() {
+		if err := pruneObjects[client.Object](ctx, params, list, nil, instanceLabels(params, nil)); err != nil {
+			return nil, fmt.Errorf("failed to clean up: %w", err)
+		}
+	}
+
+	// objects with finalizers of their own are only gone once these are done
+	var remaining []string
+	for _, list := range unownedLists() {
+		if err := params.Client.List(ctx, list, instanceLabels(params, nil)); err != nil {
+			return nil, fmt.Errorf("failed to list the remaining objects: %w", err)
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract the remaining objects: %w", err)
+		}
+		for _, item := range items {
+			obj, ok := item.(client.Object)
+			if !ok {
+				continue
+			}
+			gvk, err := apiutil.GVKForObject(obj, params.Scheme)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get the kind of %q: %w", obj.GetName(), err)
+			}
+			remaining = append(remaining, fmt.Sprintf("%s %s", gvk.Kind, obj.GetName()))
+		}
+	}
+	sort.Strings(remaining)
+
+	// nothing is applied for a deleted instance anymore, the objects it owns are garbage collected
+	verifiedVersions.Delete(params.Instance.UID)
+	return remaining, nil
+}
+
+// UpdateCleanupStatus reports the objects the cleanup of the deleted instance in the current context is waiting for.
+func UpdateCleanupStatus(ctx context.Context, params Params, remaining []string) error {
+	changed := params.Instance.DeepCopy()
+	meta.SetStatusCondition(&changed.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConditionTerminating,
+		Status:             metav1.ConditionTrue,
+		Reason:             "CleaningUp",
+		Message:            fmt.Sprintf("waiting for the deletion of %d objects: %s", len(remaining), strings.Join(remaining, ", ")),
+		ObservedGeneration: params.Instance.Generation,
+	})
+
+	patch := client.MergeFrom(&params.Instance)
+	if err := params.Client.Status().Patch(ctx, changed, patch); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to apply status changes to the OpenTelemetry CR: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
+)
+
+func TestCleanUp(t *testing.T) {
+	t.Run("should delete the cluster-scoped objects of the instance only", func(t *testing.T) {
+		p := params()
+		p.Instance.Name = "test-cleanup"
+		role := collector.ClusterRole(logger, p.Instance, "agent")
+		binding := collector.ClusterRoleBinding(p.Instance, "agent")
+		createObjectIfNotExists(t, role.Name, &role)
+		createObjectIfNotExists(t, binding.Name, &binding)
+
+		other := params()
+		other.Instance.Name = "test-cleanup-other"
+		otherRole := collector.ClusterRole(logger, other.Instance, "agent")
+		createObjectIfNotExists(t, otherRole.Name, &otherRole)
+		instanceVerifiedVersions(p).Store(role.UID, "1")
+
+		remaining, err := CleanUp(context.Background(), p)
+		require.NoError(t, err)
+		assert.Empty(t, remaining)
+		_, verified := verifiedVersions.Load(p.Instance.UID)
+		assert.False(t, verified, "the verified versions of the instance should be forgotten")
+
+		exists, err := populateObjectIfExists(t, &rbacv1.ClusterRole{}, types.NamespacedName{Name: role.Name})
+		assert.NoError(t, err)
+		assert.False(t, exists)
+		exists, err = populateObjectIfExists(t, &rbacv1.ClusterRoleBinding{}, types.NamespacedName{Name: binding.Name})
+		assert.NoError(t, err)
+		assert.False(t, exists)
+		exists, err = populateObjectIfExists(t, &rbacv1.ClusterRole{}, types.NamespacedName{Name: otherRole.Name})
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+}
@@ -20,10 +20,7 @@ import (
 	"fmt"
 
 	appsv1 "k8s.io/api/apps/v1"
-	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/naming"
@@ -33,107 +30,44 @@ import (
 
 // ClusterReceiver reconciles the Splunk Cluster Receiver required for the instance in the current context.
 func ClusterReceivers(ctx context.Context, params Params) error {
-	desired := []appsv1.Deployment{}
-	if params.Instance.Spec.ClusterReceiver.Enabled == nil || *params.Instance.Spec.ClusterReceiver.Enabled {
-		// TODO(splunk): pass params.Instance.Spec.ClusterReceiver instead of params.Instance
-		desired = append(desired, collector.ClusterReceiver(params.Log, params.Instance))
+	// the workload is left as is, including when the component gets disabled
+	if params.Instance.ComponentPaused("cluster-receiver") {
+		params.Log.V(2).Info("reconciliation paused", "component", "cluster-receiver")
+		return nil
 	}
 
-	// first, handle the create/update parts
-	if err := expectedClusterReceivers(ctx, params, desired); err != nil {
-		return fmt.Errorf("failed to reconcile the expected deployments: %w", err)
+	// the collectors would crash-loop until the access token synced by the operator is available
+	pending, err := accessTokenPending(ctx, params)
+	if err != nil {
+		return err
 	}
-
-	// then, delete the extra objects
-	if err := deleteClusterReceivers(ctx, params, desired); err != nil {
-		return fmt.Errorf("failed to reconcile the deployments to be deleted: %w", err)
+	if pending {
+		params.Log.V(2).Info("waiting for the access token", "component", "cluster-receiver")
+		return nil
 	}
 
-	return nil
-}
-
-func expectedClusterReceivers(ctx context.Context, params Params, expected []appsv1.Deployment) error {
-	for _, obj := range expected {
-		desired := obj
-
-		if err := controllerutil.SetControllerReference(&params.Instance, &desired, params.Scheme); err != nil {
-			return fmt.Errorf("failed to set controller reference: %w", err)
-		}
-
-		existing := &appsv1.Deployment{}
-		nns := types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}
-		err := params.Client.Get(ctx, nns, existing)
-		if err != nil && k8serrors.IsNotFound(err) {
-			if err = params.Client.Create(ctx, &desired); err != nil {
-				return fmt.Errorf("failed to create: %w", err)
-			}
-			params.Log.V(2).Info("created", "deployment.name", desired.Name, "deployment.namespace", desired.Namespace)
-			continue
-		} else if err != nil {
-			return fmt.Errorf("failed to get: %w", err)
-		}
-
-		// it exists already, merge the two if the end result isn't identical to the existing one
-		updated := existing.DeepCopy()
-		if updated.Annotations == nil {
-			updated.Annotations = map[string]string{}
-		}
-		if updated.Labels == nil {
-			updated.Labels = map[string]string{}
-		}
-
-		updated.Spec = desired.Spec
-		updated.ObjectMeta.OwnerReferences = desired.ObjectMeta.OwnerReferences
-
-		for k, v := range desired.ObjectMeta.Annotations {
-			updated.ObjectMeta.Annotations[k] = v
-		}
-		for k, v := range desired.ObjectMeta.Labels {
-			updated.ObjectMeta.Labels[k] = v
-		}
-
-		patch := client.MergeFrom(existing)
-
-		if err := params.Client.Patch(ctx, updated, patch); err != nil {
-			return fmt.Errorf("failed to apply changes: %w", err)
+	desired := []*appsv1.Deployment{}
+	if params.Instance.Spec.ClusterReceiver.Enabled == nil || *params.Instance.Spec.ClusterReceiver.Enabled {
+		// TODO(splunk): pass params.Instance.Spec.ClusterReceiver instead of params.Instance
+		obj := collector.ClusterReceiver(params.Log, params.Instance)
+		if err := withConfigHash(ctx, params, "cluster-receiver", &obj.Spec.Template); err != nil {
+			return fmt.Errorf("failed to hash the cluster receiver configuration: %w", err)
 		}
-
-		params.Log.V(2).Info("applied", "deployment.name", desired.Name, "deployment.namespace", desired.Namespace)
+		desired = append(desired, &obj)
 	}
 
-	return nil
-}
-
-func deleteClusterReceivers(ctx context.Context, params Params, expected []appsv1.Deployment) error {
-	opts := []client.ListOption{
-		client.InNamespace(params.Instance.Namespace),
-		client.MatchingLabels(map[string]string{
-			"app.kubernetes.io/instance":   fmt.Sprintf("%s.%s", params.Instance.Namespace, params.Instance.Name),
-			"app.kubernetes.io/managed-by": "splunk-otel-collector-operator",
-			// Without this name label match, this method could delete gateway deployments.
-			"app.kubernetes.io/name": naming.ClusterReceiver(params.Instance),
-		}),
+	// first, handle the create/update parts
+	if err := applyObjects(ctx, params, desired); err != nil {
+		return fmt.Errorf("failed to reconcile the expected deployments: %w", err)
 	}
-	list := &appsv1.DeploymentList{}
-	if err := params.Client.List(ctx, list, opts...); err != nil {
-		return fmt.Errorf("failed to list: %w", err)
+	if err := removeWorkloadConfigHash(ctx, params, desired); err != nil {
+		return fmt.Errorf("failed to reconcile the expected deployments: %w", err)
 	}
 
-	for i := range list.Items {
-		existing := list.Items[i]
-		del := true
-		for _, keep := range expected {
-			if keep.Name == existing.Name && keep.Namespace == existing.Namespace {
-				del = false
-			}
-		}
-
-		if del {
-			if err := params.Client.Delete(ctx, &existing); err != nil {
-				return fmt.Errorf("failed to delete: %w", err)
-			}
-			params.Log.V(2).Info("deleted", "deployment.name", existing.Name, "deployment.namespace", existing.Namespace)
-		}
+	// then, delete the extra objects
+	selector := instanceLabels(params, map[string]string{"app.kubernetes.io/name": naming.ClusterReceiver(params.Instance)})
+	if err := pruneObjects(ctx, params, &appsv1.DeploymentList{}, desired, client.InNamespace(params.Instance.Namespace), selector); err != nil {
+		return fmt.Errorf("failed to reconcile the deployments to be deleted: %w", err)
 	}
 
 	return nil
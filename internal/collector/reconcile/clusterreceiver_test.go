@@ -24,6 +24,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/naming"
@@ -34,7 +35,7 @@ func TestExpectedClusterReceivers(t *testing.T) {
 	expectedDeploy := collector.ClusterReceiver(logger, param.Instance)
 
 	t.Run("should create collector deployment", func(t *testing.T) {
-		err := expectedClusterReceivers(context.Background(), param, []v1.Deployment{expectedDeploy})
+		err := applyObjects(context.Background(), param, []*v1.Deployment{&expectedDeploy})
 		assert.NoError(t, err)
 
 		exists, err := populateObjectIfExists(t, &v1.Deployment{}, types.NamespacedName{Namespace: "default", Name: "test-cluster-receiver"})
@@ -46,7 +47,7 @@ func TestExpectedClusterReceivers(t *testing.T) {
 
 	t.Run("should update deployment", func(t *testing.T) {
 		createObjectIfNotExists(t, "test-cluster-receiver", &expectedDeploy)
-		err := expectedClusterReceivers(context.Background(), param, []v1.Deployment{expectedDeploy})
+		err := applyObjects(context.Background(), param, []*v1.Deployment{&expectedDeploy})
 		assert.NoError(t, err)
 
 		actual := v1.Deployment{}
@@ -86,7 +87,7 @@ func TestExpectedClusterReceivers(t *testing.T) {
 		}
 		createObjectIfNotExists(t, "dummy", &deploy)
 
-		err := deleteClusterReceivers(context.Background(), param, []v1.Deployment{expectedDeploy})
+		err := pruneObjects(context.Background(), param, &v1.DeploymentList{}, []*v1.Deployment{&expectedDeploy}, client.InNamespace("default"), instanceLabels(param, map[string]string{"app.kubernetes.io/name": naming.ClusterReceiver(param.Instance)}))
 		assert.NoError(t, err)
 
 		actual := v1.Deployment{}
@@ -123,7 +124,7 @@ func TestExpectedClusterReceivers(t *testing.T) {
 		}
 		createObjectIfNotExists(t, "dummy", &deploy)
 
-		err := deleteClusterReceivers(context.Background(), param, []v1.Deployment{expectedDeploy})
+		err := pruneObjects(context.Background(), param, &v1.DeploymentList{}, []*v1.Deployment{&expectedDeploy}, client.InNamespace("default"), instanceLabels(param, map[string]string{"app.kubernetes.io/name": naming.ClusterReceiver(param.Instance)}))
 		assert.NoError(t, err)
 
 		actual := v1.Deployment{}
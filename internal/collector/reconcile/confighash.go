@@ -0,0 +1,94 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
+)
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// withConfigHash stamps the hash of the effective configuration of the component on its pod template, so that the
+// pods are rolled out when the config, the config overlay or any of the Secrets and ConfigMaps it references change.
+// Referenced objects that don't exist yet are left out: creating them changes the hash.
+func withConfigHash(ctx context.Context, params Params, component string, template *corev1.PodTemplateSpec) error {
+	spec := params.Instance.CollectorSpec(component)
+	secrets, configMaps := collector.ReferencedObjects(*spec)
+	referenced := map[string]map[string][]byte{}
+
+	for _, name := range secrets {
+		secret := &corev1.Secret{}
+		nns := types.NamespacedName{Namespace: params.Instance.Namespace, Name: name}
+		if err := params.Client.Get(ctx, nns, secret); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get the secret %q: %w", name, err)
+		}
+		referenced["secret/"+name] = secret.Data
+	}
+
+	for _, name := range configMaps {
+		configMap := &corev1.ConfigMap{}
+		nns := types.NamespacedName{Namespace: params.Instance.Namespace, Name: name}
+		if err := params.Client.Get(ctx, nns, configMap); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get the config map %q: %w", name, err)
+		}
+		data := map[string][]byte{}
+		for k, v := range configMap.Data {
+			data[k] = []byte(v)
+		}
+		for k, v := range configMap.BinaryData {
+			data[k] = v
+		}
+		referenced["configmap/"+name] = data
+	}
+
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[collector.ConfigHashAnnotation] = collector.ConfigHash(*spec, referenced)
+	return nil
+}
+
+// removeWorkloadConfigHash removes the config hash older operators set on the workloads instead of their pod
+// templates. It was set by another field manager, applying the workloads without it doesn't remove it.
+func removeWorkloadConfigHash[T client.Object](ctx context.Context, params Params, workloads []T) error {
+	for _, obj := range workloads {
+		annotations := obj.GetAnnotations()
+		if _, ok := annotations[collector.ConfigHashAnnotation]; !ok {
+			continue
+		}
+
+		patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		delete(annotations, collector.ConfigHashAnnotation)
+		obj.SetAnnotations(annotations)
+		if err := params.Client.Patch(ctx, obj, patch); err != nil {
+			return fmt.Errorf("failed to remove the config hash of %q: %w", obj.GetName(), err)
+		}
+	}
+	return nil
+}
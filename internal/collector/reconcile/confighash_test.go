@@ -0,0 +1,66 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
+)
+
+func TestWithConfigHash(t *testing.T) {
+	param := params()
+	param.Instance.Spec.Gateway.Env = []corev1.EnvVar{{
+		Name: "SPLUNK_ACCESS_TOKEN",
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "config-hash-token"},
+				Key:                  "access-token",
+			},
+		},
+	}}
+
+	hash := func(component string) string {
+		template := corev1.PodTemplateSpec{}
+		require.NoError(t, withConfigHash(context.Background(), param, component, &template))
+		return template.Annotations[collector.ConfigHashAnnotation]
+	}
+
+	t.Run("should ignore missing references", func(t *testing.T) {
+		assert.Equal(t, collector.ConfigHash(param.Instance.Spec.Gateway, nil), hash("gateway"))
+	})
+
+	t.Run("should change when a referenced secret changes", func(t *testing.T) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "config-hash-token", Namespace: "default"},
+			Data:       map[string][]byte{"access-token": []byte("old")},
+		}
+		require.NoError(t, k8sClient.Create(context.Background(), secret))
+		before := hash("gateway")
+		agent := hash("agent")
+
+		secret.Data["access-token"] = []byte("new")
+		require.NoError(t, k8sClient.Update(context.Background(), secret))
+
+		assert.NotEqual(t, before, hash("gateway"))
+		assert.Equal(t, agent, hash("agent"), "components not referencing the secret shouldn't be rolled out")
+	})
+}
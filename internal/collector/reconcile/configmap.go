@@ -23,11 +23,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/adapters"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/naming"
 )
 
@@ -35,25 +35,48 @@ import (
 
 // ConfigMaps reconciles the config map(s) required for the instance in the current context.
 func ConfigMaps(ctx context.Context, params Params) error {
-	desired := []corev1.ConfigMap{}
+	desired, kept := []*corev1.ConfigMap{}, []*corev1.ConfigMap{}
+	for _, component := range v1alpha1.Components {
+		if params.Instance.ComponentPaused(component) {
+			// only the name matters to keep the config map from being deleted
+			kept = append(kept, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Name:      naming.ConfigMap(params.Instance, component),
+				Namespace: params.Instance.Namespace,
+			}})
+			continue
+		}
+		if !params.Instance.ComponentEnabled(component) {
+			continue
+		}
 
-	if params.Instance.Spec.Agent.Enabled == nil || *params.Instance.Spec.Agent.Enabled {
-		desired = append(desired, desiredConfigMap(ctx, params, params.Instance.Spec.Agent.Config, "agent"))
-	}
-	if params.Instance.Spec.ClusterReceiver.Enabled == nil || *params.Instance.Spec.ClusterReceiver.Enabled {
-		desired = append(desired, desiredConfigMap(ctx, params, params.Instance.Spec.ClusterReceiver.Config, "cluster-receiver"))
+		spec := params.Instance.CollectorSpec(component)
+		config, err := adapters.ConfigWithOverlay(spec.Config, spec.ConfigOverlay)
+		if err != nil {
+			return fmt.Errorf("failed to merge the %s config overlay: %w", component, err)
+		}
+		cm := desiredConfigMap(ctx, params, config, component)
+		desired = append(desired, &cm)
 	}
-	if params.Instance.Spec.Gateway.Enabled != nil && *params.Instance.Spec.Gateway.Enabled {
-		desired = append(desired, desiredConfigMap(ctx, params, params.Instance.Spec.Gateway.Config, "gateway"))
+
+	existing, err := existingConfigMaps(ctx, params, desired)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile the expected configmaps: %w", err)
 	}
 
 	// first, handle the create/update parts
-	if err := expectedConfigMaps(ctx, params, desired, true); err != nil {
+	if err := applyObjects(ctx, params, desired); err != nil {
 		return fmt.Errorf("failed to reconcile the expected configmaps: %w", err)
 	}
 
+	// the collectors reload their config on their own, changes are only recorded as events
+	for _, cm := range desired {
+		if previous, ok := existing[cm.Name]; ok && configMapChanged(cm, previous) {
+			params.Recorder.Event(cm, "Normal", "ConfigUpdate", fmt.Sprintf("OpenTelemetry Config changed - %s/%s", cm.Namespace, cm.Name))
+		}
+	}
+
 	// then, delete the extra objects
-	if err := deleteConfigMaps(ctx, params, desired); err != nil {
+	if err := pruneObjects(ctx, params, &corev1.ConfigMapList{}, append(desired, kept...), client.InNamespace(params.Instance.Namespace), instanceLabels(params, nil)); err != nil {
 		return fmt.Errorf("failed to reconcile the configmaps to be deleted: %w", err)
 	}
 
@@ -78,106 +101,22 @@ func desiredConfigMap(_ context.Context, params Params, config, kind string) cor
 	}
 }
 
-func expectedConfigMaps(ctx context.Context, params Params, expected []corev1.ConfigMap, retry bool) error {
-	for _, obj := range expected {
-		desired := obj
-
-		if err := controllerutil.SetControllerReference(&params.Instance, &desired, params.Scheme); err != nil {
-			return fmt.Errorf("failed to set controller reference: %w", err)
-		}
-
-		existing := &corev1.ConfigMap{}
-		nns := types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}
-		err := params.Client.Get(ctx, nns, existing)
-		if err != nil && errors.IsNotFound(err) {
-			if err = params.Client.Create(ctx, &desired); err != nil {
-				if errors.IsAlreadyExists(err) && retry {
-					// let's try again? we probably had multiple updates at one, and now it exists already
-					if err = expectedConfigMaps(ctx, params, expected, false); err != nil {
-						// somethin else happened now...
-						return err
-					}
-
-					// we succeeded in the retry, exit this attempt
-					return nil
-				}
-				return fmt.Errorf("failed to create: %w", err)
+// existingConfigMaps returns the desired config maps that exist already, by name.
+func existingConfigMaps(ctx context.Context, params Params, desired []*corev1.ConfigMap) (map[string]*corev1.ConfigMap, error) {
+	existing := map[string]*corev1.ConfigMap{}
+	for _, cm := range desired {
+		actual := &corev1.ConfigMap{}
+		if err := params.Client.Get(ctx, client.ObjectKeyFromObject(cm), actual); err != nil {
+			if errors.IsNotFound(err) {
+				continue
 			}
-			params.Log.V(2).Info("created", "configmap.name", desired.Name, "configmap.namespace", desired.Namespace)
-			continue
-		} else if err != nil {
-			return fmt.Errorf("failed to get: %w", err)
-		}
-
-		// it exists already, merge the two if the end result isn't identical to the existing one
-		updated := existing.DeepCopy()
-		if updated.Annotations == nil {
-			updated.Annotations = map[string]string{}
-		}
-		if updated.Labels == nil {
-			updated.Labels = map[string]string{}
-		}
-
-		updated.Data = desired.Data
-		updated.BinaryData = desired.BinaryData
-		updated.ObjectMeta.OwnerReferences = desired.ObjectMeta.OwnerReferences
-
-		for k, v := range desired.ObjectMeta.Annotations {
-			updated.ObjectMeta.Annotations[k] = v
-		}
-		for k, v := range desired.ObjectMeta.Labels {
-			updated.ObjectMeta.Labels[k] = v
+			return nil, fmt.Errorf("failed to get: %w", err)
 		}
-
-		patch := client.MergeFrom(existing)
-
-		if err := params.Client.Patch(ctx, updated, patch); err != nil {
-			return fmt.Errorf("failed to apply changes: %w", err)
-		}
-		if configMapChanged(&desired, existing) {
-			params.Recorder.Event(updated, "Normal", "ConfigUpdate ", fmt.Sprintf("OpenTelemetry Config changed - %s/%s", desired.Namespace, desired.Name))
-		}
-
-		params.Log.V(2).Info("applied", "configmap.name", desired.Name, "configmap.namespace", desired.Namespace)
+		existing[cm.Name] = actual
 	}
-
-	return nil
-}
-
-func deleteConfigMaps(ctx context.Context, params Params, expected []corev1.ConfigMap) error {
-	opts := []client.ListOption{
-		client.InNamespace(params.Instance.Namespace),
-		client.MatchingLabels(map[string]string{
-			"app.kubernetes.io/instance":   fmt.Sprintf("%s.%s", params.Instance.Namespace, params.Instance.Name),
-			"app.kubernetes.io/managed-by": "splunk-otel-collector-operator",
-		}),
-	}
-	list := &corev1.ConfigMapList{}
-	if err := params.Client.List(ctx, list, opts...); err != nil {
-		return fmt.Errorf("failed to list: %w", err)
-	}
-
-	for i := range list.Items {
-		existing := list.Items[i]
-		del := true
-		for _, keep := range expected {
-			if keep.Name == existing.Name && keep.Namespace == existing.Namespace {
-				del = false
-			}
-		}
-
-		if del {
-			if err := params.Client.Delete(ctx, &existing); err != nil {
-				return fmt.Errorf("failed to delete: %w", err)
-			}
-			params.Log.V(2).Info("deleted", "configmap.name", existing.Name, "configmap.namespace", existing.Namespace)
-		}
-	}
-
-	return nil
+	return existing, nil
 }
 
 func configMapChanged(desired *corev1.ConfigMap, actual *corev1.ConfigMap) bool {
 	return !reflect.DeepEqual(desired.Data, actual.Data)
-
 }
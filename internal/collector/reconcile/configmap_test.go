@@ -24,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
 )
@@ -83,7 +84,7 @@ func TestExpectedConfigMap(t *testing.T) {
 		crMap := desiredConfigMap(context.Background(), p2, p2.Instance.Spec.ClusterReceiver.Config, "clusterreceiver")
 
 		p3 := params()
-		err := expectedConfigMaps(context.Background(), p3, []v1.ConfigMap{agentMap, crMap}, true)
+		err := applyObjects(context.Background(), p3, []*v1.ConfigMap{&agentMap, &crMap})
 		assert.NoError(t, err)
 
 		exists, err := populateObjectIfExists(t, &v1.ConfigMap{}, types.NamespacedName{Namespace: "default", Name: "test-agent"})
@@ -117,7 +118,7 @@ func TestExpectedConfigMap(t *testing.T) {
 		p := params()
 		desired := desiredConfigMap(context.Background(), p, p.Instance.Spec.Agent.Config, "agent")
 
-		err := expectedConfigMaps(context.Background(), params(), []v1.ConfigMap{desired}, true)
+		err := applyObjects(context.Background(), params(), []*v1.ConfigMap{&desired})
 		assert.NoError(t, err)
 
 		actual := v1.ConfigMap{}
@@ -148,7 +149,7 @@ func TestExpectedConfigMap(t *testing.T) {
 
 		p := params()
 		desired := desiredConfigMap(context.Background(), p, p.Instance.Spec.Agent.Config, "agent")
-		err := deleteConfigMaps(context.Background(), params(), []v1.ConfigMap{desired})
+		err := pruneObjects(context.Background(), params(), &v1.ConfigMapList{}, []*v1.ConfigMap{&desired}, client.InNamespace("default"), instanceLabels(params(), nil))
 		assert.NoError(t, err)
 
 		exists, _ = populateObjectIfExists(t, &v1.ConfigMap{}, types.NamespacedName{Namespace: "default", Name: "test-delete-collector"})
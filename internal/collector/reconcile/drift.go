@@ -0,0 +1,172 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+)
+
+// AppliedHashAnnotation holds the hash of the desired state the operator last applied to an object. Objects applied
+// from another desired state are being updated, changes to objects applied from the current one are drift.
+const AppliedHashAnnotation = "otel.splunk.com/applied-sha256"
+
+var driftedObjects = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "splunk_otel_operator_drifted_objects_total",
+	Help: "Number of times a managed object was found changed outside of the operator.",
+}, []string{"namespace", "agent", "kind"})
+
+func init() {
+	metrics.Registry.MustRegister(driftedObjects)
+}
+
+// verifiedVersions holds, by instance UID, the resource version of the objects last applied, or found without drift,
+// by UID. An object still at that version hasn't been changed by anyone since, there's no need to look for drift. The
+// versions of an object are forgotten when it's pruned, those of an instance with its cleanup.
+var verifiedVersions sync.Map
+
+// instanceVerifiedVersions returns the verified versions of the objects of the instance in the current context.
+func instanceVerifiedVersions(params Params) *sync.Map {
+	versions, _ := verifiedVersions.LoadOrStore(params.Instance.UID, &sync.Map{})
+	return versions.(*sync.Map)
+}
+
+// ignoredPaths are maintained by the API server, they differ from the applied state without anyone changing the object.
+var ignoredPaths = map[string]bool{
+	"status":                     true,
+	"metadata.managedFields":     true,
+	"metadata.resourceVersion":   true,
+	"metadata.generation":        true,
+	"metadata.creationTimestamp": true,
+	"metadata.uid":               true,
+}
+
+// detectDrift returns the paths of the fields set by the operator that were changed on the live object, nil when it
+// doesn't exist, since the operator applied the given desired state. A dry-run apply tells what applying the desired
+// object would change, so fields defaulted by the API server or owned by other managers aren't reported.
+func detectDrift(ctx context.Context, params Params, desired, live client.Object, hash string) ([]string, error) {
+	if live == nil || live.GetAnnotations()[AppliedHashAnnotation] != hash {
+		return nil, nil
+	}
+	if version, ok := instanceVerifiedVersions(params).Load(live.GetUID()); ok && version == live.GetResourceVersion() {
+		return nil, nil
+	}
+
+	applied := desired.DeepCopyObject().(client.Object)
+	if err := params.Client.Patch(ctx, applied, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership, client.DryRunAll); err != nil {
+		return nil, fmt.Errorf("failed to dry-run the apply: %w", err)
+	}
+
+	liveFields, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the live object: %w", err)
+	}
+	appliedFields, err := runtime.DefaultUnstructuredConverter.ToUnstructured(applied)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the applied object: %w", err)
+	}
+
+	paths := driftedPaths("", liveFields, appliedFields)
+	if len(paths) == 0 {
+		instanceVerifiedVersions(params).Store(live.GetUID(), live.GetResourceVersion())
+	}
+	return paths, nil
+}
+
+// reportDrift records an event on the instance naming the drifted fields of the object and counts it.
+func reportDrift(params Params, kind string, obj client.Object, paths []string) {
+	driftedObjects.WithLabelValues(params.Instance.Namespace, params.Instance.Name, kind).Inc()
+
+	action := "reverting the changes"
+	if params.Instance.Spec.DriftPolicy == v1alpha1.DriftPolicyReportOnly {
+		action = "leaving the changes in place"
+	}
+	params.Log.Info("drift detected", "kind", kind, "name", obj.GetName(), "namespace", obj.GetNamespace(), "paths", paths)
+	if params.Recorder != nil {
+		params.Recorder.Event(&params.Instance, "Warning", "DriftDetected", fmt.Sprintf("%s %s was changed outside of the operator, %s: %s",
+			kind, objectName(obj), action, strings.Join(paths, ", ")))
+	}
+}
+
+func objectName(obj client.Object) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// driftedPaths returns the sorted paths of the fields that differ between both unstructured objects. Lists of
+// different lengths are reported as a whole.
+func driftedPaths(prefix string, live, applied interface{}) []string {
+	if ignoredPaths[prefix] {
+		return nil
+	}
+
+	liveMap, liveIsMap := live.(map[string]interface{})
+	appliedMap, appliedIsMap := applied.(map[string]interface{})
+	if liveIsMap && appliedIsMap {
+		keys := map[string]bool{}
+		for k := range liveMap {
+			keys[k] = true
+		}
+		for k := range appliedMap {
+			keys[k] = true
+		}
+
+		var paths []string
+		for k := range keys {
+			paths = append(paths, driftedPaths(fieldPath(prefix, k), liveMap[k], appliedMap[k])...)
+		}
+		sort.Strings(paths)
+		return paths
+	}
+
+	liveList, liveIsList := live.([]interface{})
+	appliedList, appliedIsList := applied.([]interface{})
+	if liveIsList && appliedIsList && len(liveList) == len(appliedList) {
+		var paths []string
+		for i := range liveList {
+			paths = append(paths, driftedPaths(fmt.Sprintf("%s[%d]", prefix, i), liveList[i], appliedList[i])...)
+		}
+		return paths
+	}
+
+	if reflect.DeepEqual(live, applied) {
+		return nil
+	}
+	return []string{prefix}
+}
+
+// fieldPath appends the key to the path, quoting keys that aren't plain field names like label keys.
+func fieldPath(prefix, key string) string {
+	if strings.ContainsAny(key, "./") {
+		return fmt.Sprintf("%s[%q]", prefix, key)
+	}
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
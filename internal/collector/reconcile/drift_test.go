@@ -0,0 +1,150 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+)
+
+func TestDriftedPaths(t *testing.T) {
+	applied := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":            "test-agent",
+			"resourceVersion": "2",
+			"labels":          map[string]interface{}{"app.kubernetes.io/name": "test-agent"},
+		},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{"app": "test-agent"},
+			"ports": []interface{}{
+				map[string]interface{}{"name": "otlp", "port": int64(4317)},
+			},
+		},
+		"status": map[string]interface{}{"ready": int64(1)},
+	}
+
+	for _, tt := range []struct {
+		desc     string
+		live     map[string]interface{}
+		expected []string
+	}{
+		{
+			desc: "unchanged",
+			live: applied,
+		},
+		{
+			desc: "changed fields",
+			live: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":            "test-agent",
+					"resourceVersion": "3",
+					"labels":          map[string]interface{}{"app.kubernetes.io/name": "changed"},
+				},
+				"spec": map[string]interface{}{
+					"selector": map[string]interface{}{"app": "changed"},
+					"ports": []interface{}{
+						map[string]interface{}{"name": "otlp", "port": int64(4318)},
+					},
+				},
+				"status": map[string]interface{}{"ready": int64(0)},
+			},
+			expected: []string{`metadata.labels["app.kubernetes.io/name"]`, "spec.ports[0].port", "spec.selector.app"},
+		},
+		{
+			desc: "removed fields",
+			live: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":   "test-agent",
+					"labels": map[string]interface{}{"app.kubernetes.io/name": "test-agent"},
+				},
+				"spec": map[string]interface{}{
+					"ports": []interface{}{},
+				},
+			},
+			expected: []string{"spec.ports", "spec.selector"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.expected, driftedPaths("", tt.live, applied))
+		})
+	}
+}
+
+func TestAppliedHash(t *testing.T) {
+	p := params()
+	annotations := map[string]string{"team": "observability"}
+
+	first := desiredConfigMap(context.Background(), p, "receivers:", "agent")
+	first.Annotations = annotations
+	hash, err := appliedHash(&first)
+	require.NoError(t, err)
+	assert.Equal(t, hash, first.Annotations[AppliedHashAnnotation])
+	assert.NotContains(t, annotations, AppliedHashAnnotation, "the annotations of the desired object must be copied")
+
+	// hashing an applied object once again gives the same hash
+	again, err := appliedHash(&first)
+	require.NoError(t, err)
+	assert.Equal(t, hash, again)
+
+	second := desiredConfigMap(context.Background(), p, "exporters:", "agent")
+	second.Annotations = annotations
+	other, err := appliedHash(&second)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+}
+
+func TestDriftPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		policy   v1alpha1.DriftPolicy
+		expected string
+	}{
+		{policy: v1alpha1.DriftPolicyReportOnly, expected: "changed"},
+		{policy: v1alpha1.DriftPolicyEnforce, expected: "receivers:"},
+	} {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			p := params()
+			p.Recorder = recorder
+			p.Instance.Spec.DriftPolicy = tt.policy
+
+			desired := desiredConfigMap(context.Background(), p, "receivers:", "drift-"+string(tt.policy))
+			require.NoError(t, applyObjects(context.Background(), p, []*v1.ConfigMap{&desired}))
+
+			// someone edits the config map by hand
+			existing := &v1.ConfigMap{}
+			require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(&desired), existing))
+			existing.Data["collector.yaml"] = "changed"
+			require.NoError(t, k8sClient.Update(context.Background(), existing))
+
+			desired = desiredConfigMap(context.Background(), p, "receivers:", "drift-"+string(tt.policy))
+			require.NoError(t, applyObjects(context.Background(), p, []*v1.ConfigMap{&desired}))
+
+			actual := &v1.ConfigMap{}
+			require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(&desired), actual))
+			assert.Equal(t, tt.expected, actual.Data["collector.yaml"])
+
+			require.Len(t, recorder.Events, 1)
+			assert.Contains(t, <-recorder.Events, `data["collector.yaml"]`)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TaskFailure describes a reconciliation task that returned an error.
+type TaskFailure struct {
+	Task string
+	Err  error
+}
+
+// Error returns the message reported for the failure in the events and conditions of the instance.
+func (f TaskFailure) Error() string {
+	return fmt.Sprintf("failed to reconcile %s: %v", f.Task, f.Err)
+}
+
+// Reason returns the condition reason for the failure, like "ConfigMapsFailed" for the "config maps" task.
+func (f TaskFailure) Reason() string {
+	var reason strings.Builder
+	for _, word := range strings.Fields(f.Task) {
+		reason.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	reason.WriteString("Failed")
+	return reason.String()
+}
@@ -20,120 +20,82 @@ import (
 	"fmt"
 
 	appsv1 "k8s.io/api/apps/v1"
-	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/naming"
 )
 
 // +kubebuilder:rbac:groups="apps",resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="autoscaling",resources=horizontalpodautoscalers,verbs=get;list;watch
 
 // Gateway reconciles the Splunk Otel Gateway required for the instance in the current context.
 func Gateways(ctx context.Context, params Params) error {
-	desired := []appsv1.Deployment{}
-	if params.Instance.Spec.Gateway.Enabled != nil && *params.Instance.Spec.Gateway.Enabled {
-		// TODO(splunk): pass params.Instance.Spec.Gateway instead of params.Instance
-		desired = append(desired, collector.Gateway(params.Log, params.Instance))
+	// the workload is left as is, including when the component gets disabled
+	if params.Instance.ComponentPaused("gateway") {
+		params.Log.V(2).Info("reconciliation paused", "component", "gateway")
+		return nil
 	}
 
-	// first, handle the create/update parts
-	if err := expectedGateways(ctx, params, desired); err != nil {
-		return fmt.Errorf("failed to reconcile the expected deployments: %w", err)
+	// the collectors would crash-loop until the access token synced by the operator is available
+	pending, err := accessTokenPending(ctx, params)
+	if err != nil {
+		return err
 	}
-
-	// then, delete the extra objects
-	if err := deleteGateways(ctx, params, desired); err != nil {
-		return fmt.Errorf("failed to reconcile the deployments to be deleted: %w", err)
+	if pending {
+		params.Log.V(2).Info("waiting for the access token", "component", "gateway")
+		return nil
 	}
 
-	return nil
-}
-
-func expectedGateways(ctx context.Context, params Params, expected []appsv1.Deployment) error {
-	for _, obj := range expected {
-		desired := obj
-
-		if err := controllerutil.SetControllerReference(&params.Instance, &desired, params.Scheme); err != nil {
-			return fmt.Errorf("failed to set controller reference: %w", err)
-		}
-
-		existing := &appsv1.Deployment{}
-		nns := types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}
-		err := params.Client.Get(ctx, nns, existing)
-		if err != nil && k8serrors.IsNotFound(err) {
-			if err = params.Client.Create(ctx, &desired); err != nil {
-				return fmt.Errorf("failed to create: %w", err)
-			}
-			params.Log.V(2).Info("created", "deployment.name", desired.Name, "deployment.namespace", desired.Namespace)
-			continue
-		} else if err != nil {
-			return fmt.Errorf("failed to get: %w", err)
-		}
-
-		// it exists already, merge the two if the end result isn't identical to the existing one
-		updated := existing.DeepCopy()
-		if updated.Annotations == nil {
-			updated.Annotations = map[string]string{}
-		}
-		if updated.Labels == nil {
-			updated.Labels = map[string]string{}
+	desired := []*appsv1.Deployment{}
+	if params.Instance.Spec.Gateway.Enabled != nil && *params.Instance.Spec.Gateway.Enabled {
+		// TODO(splunk): pass params.Instance.Spec.Gateway instead of params.Instance
+		obj := collector.Gateway(params.Log, params.Instance)
+		if err := withConfigHash(ctx, params, "gateway", &obj.Spec.Template); err != nil {
+			return fmt.Errorf("failed to hash the gateway configuration: %w", err)
 		}
 
-		updated.Spec = desired.Spec
-		updated.ObjectMeta.OwnerReferences = desired.ObjectMeta.OwnerReferences
-
-		for k, v := range desired.ObjectMeta.Annotations {
-			updated.ObjectMeta.Annotations[k] = v
+		// the replicas are left to the HPA scaling the gateway, applying them would take them back from it
+		autoscaled, err := isAutoscaled(ctx, params, &obj)
+		if err != nil {
+			return err
 		}
-		for k, v := range desired.ObjectMeta.Labels {
-			updated.ObjectMeta.Labels[k] = v
+		if autoscaled {
+			obj.Spec.Replicas = nil
 		}
+		desired = append(desired, &obj)
+	}
 
-		patch := client.MergeFrom(existing)
-
-		if err := params.Client.Patch(ctx, updated, patch); err != nil {
-			return fmt.Errorf("failed to apply changes: %w", err)
-		}
+	// first, handle the create/update parts
+	if err := applyObjects(ctx, params, desired); err != nil {
+		return fmt.Errorf("failed to reconcile the expected deployments: %w", err)
+	}
+	if err := removeWorkloadConfigHash(ctx, params, desired); err != nil {
+		return fmt.Errorf("failed to reconcile the expected deployments: %w", err)
+	}
 
-		params.Log.V(2).Info("applied", "deployment.name", desired.Name, "deployment.namespace", desired.Namespace)
+	// then, delete the extra objects
+	selector := instanceLabels(params, map[string]string{"app.kubernetes.io/name": naming.Gateway(params.Instance)})
+	if err := pruneObjects(ctx, params, &appsv1.DeploymentList{}, desired, client.InNamespace(params.Instance.Namespace), selector); err != nil {
+		return fmt.Errorf("failed to reconcile the deployments to be deleted: %w", err)
 	}
 
 	return nil
 }
 
-func deleteGateways(ctx context.Context, params Params, expected []appsv1.Deployment) error {
-	opts := []client.ListOption{
-		client.InNamespace(params.Instance.Namespace),
-		client.MatchingLabels(map[string]string{
-			"app.kubernetes.io/instance":   fmt.Sprintf("%s.%s", params.Instance.Namespace, params.Instance.Name),
-			"app.kubernetes.io/managed-by": "splunk-otel-collector-operator",
-			"app.kubernetes.io/name":       naming.Gateway(params.Instance),
-		}),
-	}
-	list := &appsv1.DeploymentList{}
-	if err := params.Client.List(ctx, list, opts...); err != nil {
-		return fmt.Errorf("failed to list: %w", err)
+// isAutoscaled tells whether an HPA of the namespace of the instance scales the given deployment.
+func isAutoscaled(ctx context.Context, params Params, deployment *appsv1.Deployment) (bool, error) {
+	list := &autoscalingv1.HorizontalPodAutoscalerList{}
+	if err := params.Client.List(ctx, list, client.InNamespace(deployment.Namespace)); err != nil {
+		return false, fmt.Errorf("failed to list the horizontal pod autoscalers: %w", err)
 	}
 
-	for i := range list.Items {
-		existing := list.Items[i]
-		del := true
-		for _, keep := range expected {
-			if keep.Name == existing.Name && keep.Namespace == existing.Namespace {
-				del = false
-			}
-		}
-
-		if del {
-			if err := params.Client.Delete(ctx, &existing); err != nil {
-				return fmt.Errorf("failed to delete: %w", err)
-			}
-			params.Log.V(2).Info("deleted", "deployment.name", existing.Name, "deployment.namespace", existing.Namespace)
+	for _, hpa := range list.Items {
+		target := hpa.Spec.ScaleTargetRef
+		if target.Kind == "Deployment" && target.Name == deployment.Name {
+			return true, nil
 		}
 	}
-
-	return nil
+	return false, nil
 }
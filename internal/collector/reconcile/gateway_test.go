@@ -20,10 +20,13 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/naming"
@@ -34,7 +37,7 @@ func TestExpectedGateways(t *testing.T) {
 	expectedDeploy := collector.Gateway(logger, param.Instance)
 
 	t.Run("should create collector deployment", func(t *testing.T) {
-		err := expectedGateways(context.Background(), param, []v1.Deployment{expectedDeploy})
+		err := applyObjects(context.Background(), param, []*v1.Deployment{&expectedDeploy})
 		assert.NoError(t, err)
 
 		exists, err := populateObjectIfExists(t, &v1.Deployment{}, types.NamespacedName{Namespace: "default", Name: "test-gateway"})
@@ -46,7 +49,7 @@ func TestExpectedGateways(t *testing.T) {
 
 	t.Run("should update deployment", func(t *testing.T) {
 		createObjectIfNotExists(t, "test-gateway", &expectedDeploy)
-		err := expectedGateways(context.Background(), param, []v1.Deployment{expectedDeploy})
+		err := applyObjects(context.Background(), param, []*v1.Deployment{&expectedDeploy})
 		assert.NoError(t, err)
 
 		actual := v1.Deployment{}
@@ -86,7 +89,7 @@ func TestExpectedGateways(t *testing.T) {
 		}
 		createObjectIfNotExists(t, "dummy-gateway", &deploy)
 
-		err := deleteGateways(context.Background(), param, []v1.Deployment{expectedDeploy})
+		err := pruneObjects(context.Background(), param, &v1.DeploymentList{}, []*v1.Deployment{&expectedDeploy}, client.InNamespace("default"), instanceLabels(param, map[string]string{"app.kubernetes.io/name": naming.Gateway(param.Instance)}))
 		assert.NoError(t, err)
 
 		actual := v1.Deployment{}
@@ -123,7 +126,7 @@ func TestExpectedGateways(t *testing.T) {
 		}
 		createObjectIfNotExists(t, "dummy-gateway", &deploy)
 
-		err := deleteGateways(context.Background(), param, []v1.Deployment{expectedDeploy})
+		err := pruneObjects(context.Background(), param, &v1.DeploymentList{}, []*v1.Deployment{&expectedDeploy}, client.InNamespace("default"), instanceLabels(param, map[string]string{"app.kubernetes.io/name": naming.Gateway(param.Instance)}))
 		assert.NoError(t, err)
 
 		actual := v1.Deployment{}
@@ -133,3 +136,28 @@ func TestExpectedGateways(t *testing.T) {
 
 	})
 }
+
+func TestGatewayAutoscaled(t *testing.T) {
+	param := params()
+	gateway := collector.Gateway(logger, param.Instance)
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gateway-hpa", Namespace: "default"},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: gateway.Name},
+			MaxReplicas:    5,
+		},
+	}
+
+	autoscaled, err := isAutoscaled(context.Background(), param, &gateway)
+	require.NoError(t, err)
+	assert.False(t, autoscaled)
+
+	require.NoError(t, k8sClient.Create(context.Background(), hpa))
+	defer func() {
+		_ = k8sClient.Delete(context.Background(), hpa)
+	}()
+
+	autoscaled, err = isAutoscaled(context.Background(), param, &gateway)
+	require.NoError(t, err)
+	assert.True(t, autoscaled)
+}
@@ -0,0 +1,178 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/naming"
+)
+
+// the rules of the collectors follow their config, the operator therefore needs to grant and bind permissions it
+// doesn't hold itself
+// +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterroles;clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterroles,verbs=escalate;bind
+// +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=roles,verbs=escalate;bind
+
+// ClusterRoles reconciles the cluster roles of the components of the instance in the current context. Cluster roles
+// can't be owned by a namespaced object, so they're matched with their instance by labels instead.
+func ClusterRoles(ctx context.Context, params Params) error {
+	desired, kept := []*rbacv1.ClusterRole{}, []*rbacv1.ClusterRole{}
+	for _, component := range v1alpha1.Components {
+		if params.Instance.ComponentPaused(component) {
+			kept = append(kept, &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: naming.ClusterRole(params.Instance, component)}})
+			continue
+		}
+		if params.Instance.ComponentEnabled(component) {
+			role := collector.ClusterRole(params.Log, params.Instance, component)
+			desired = append(desired, &role)
+		}
+	}
+
+	// first, handle the create/update parts
+	if err := applyObjects(ctx, params, desired); err != nil {
+		return fmt.Errorf("failed to reconcile the expected cluster roles: %w", err)
+	}
+
+	// then, delete the extra objects
+	if err := pruneObjects(ctx, params, &rbacv1.ClusterRoleList{}, append(desired, kept...), instanceLabels(params, nil)); err != nil {
+		return fmt.Errorf("failed to reconcile the cluster roles to be deleted: %w", err)
+	}
+
+	return nil
+}
+
+// ClusterRoleBindings reconciles the bindings of the cluster roles to the service accounts of the components of the
+// instance in the current context.
+func ClusterRoleBindings(ctx context.Context, params Params) error {
+	desired, kept := []*rbacv1.ClusterRoleBinding{}, []*rbacv1.ClusterRoleBinding{}
+	for _, component := range v1alpha1.Components {
+		if params.Instance.ComponentPaused(component) {
+			kept = append(kept, &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: naming.ClusterRole(params.Instance, component)}})
+			continue
+		}
+		if params.Instance.ComponentEnabled(component) {
+			binding := collector.ClusterRoleBinding(params.Instance, component)
+			desired = append(desired, &binding)
+		}
+	}
+
+	// the role of a binding can't be changed, bindings to another role have to be recreated instead
+	if err := deleteRebound(ctx, params, desired); err != nil {
+		return fmt.Errorf("failed to reconcile the expected cluster role bindings: %w", err)
+	}
+
+	// first, handle the create/update parts
+	if err := applyObjects(ctx, params, desired); err != nil {
+		return fmt.Errorf("failed to reconcile the expected cluster role bindings: %w", err)
+	}
+
+	// then, delete the extra objects
+	if err := pruneObjects(ctx, params, &rbacv1.ClusterRoleBindingList{}, append(desired, kept...), instanceLabels(params, nil)); err != nil {
+		return fmt.Errorf("failed to reconcile the cluster role bindings to be deleted: %w", err)
+	}
+
+	return nil
+}
+
+// Roles reconciles the roles of the components of the instance in the current context, for the components whose
+// config needs permissions in the namespace of the instance.
+func Roles(ctx context.Context, params Params) error {
+	desired, kept := []*rbacv1.Role{}, []*rbacv1.Role{}
+	for _, component := range v1alpha1.Components {
+		if params.Instance.ComponentPaused(component) {
+			kept = append(kept, &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: naming.Role(params.Instance, component), Namespace: params.Instance.Namespace}})
+			continue
+		}
+		if params.Instance.ComponentEnabled(component) {
+			if role := collector.Role(params.Log, params.Instance, component); len(role.Rules) > 0 {
+				desired = append(desired, &role)
+			}
+		}
+	}
+
+	// first, handle the create/update parts
+	if err := applyObjects(ctx, params, desired); err != nil {
+		return fmt.Errorf("failed to reconcile the expected roles: %w", err)
+	}
+
+	// then, delete the extra objects
+	if err := pruneObjects(ctx, params, &rbacv1.RoleList{}, append(desired, kept...), client.InNamespace(params.Instance.Namespace), instanceLabels(params, nil)); err != nil {
+		return fmt.Errorf("failed to reconcile the roles to be deleted: %w", err)
+	}
+
+	return nil
+}
+
+// RoleBindings reconciles the bindings of the roles to the service accounts of the components of the instance in the
+// current context.
+func RoleBindings(ctx context.Context, params Params) error {
+	desired, kept := []*rbacv1.RoleBinding{}, []*rbacv1.RoleBinding{}
+	for _, component := range v1alpha1.Components {
+		if params.Instance.ComponentPaused(component) {
+			kept = append(kept, &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: naming.Role(params.Instance, component), Namespace: params.Instance.Namespace}})
+			continue
+		}
+		if params.Instance.ComponentEnabled(component) {
+			if role := collector.Role(params.Log, params.Instance, component); len(role.Rules) > 0 {
+				binding := collector.RoleBinding(params.Instance, component)
+				desired = append(desired, &binding)
+			}
+		}
+	}
+
+	// first, handle the create/update parts
+	if err := applyObjects(ctx, params, desired); err != nil {
+		return fmt.Errorf("failed to reconcile the expected role bindings: %w", err)
+	}
+
+	// then, delete the extra objects
+	if err := pruneObjects(ctx, params, &rbacv1.RoleBindingList{}, append(desired, kept...), client.InNamespace(params.Instance.Namespace), instanceLabels(params, nil)); err != nil {
+		return fmt.Errorf("failed to reconcile the role bindings to be deleted: %w", err)
+	}
+
+	return nil
+}
+
+// deleteRebound deletes the existing bindings that bind another role than their desired counterpart.
+func deleteRebound(ctx context.Context, params Params, desired []*rbacv1.ClusterRoleBinding) error {
+	for _, binding := range desired {
+		existing := &rbacv1.ClusterRoleBinding{}
+		if err := params.Client.Get(ctx, client.ObjectKeyFromObject(binding), existing); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get: %w", err)
+		}
+
+		if existing.RoleRef != binding.RoleRef {
+			if err := params.Client.Delete(ctx, existing); err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete the binding to %q: %w", existing.RoleRef.Name, err)
+			}
+			params.Log.V(2).Info("deleted to be recreated", "clusterrolebinding.name", existing.Name)
+		}
+	}
+
+	return nil
+}
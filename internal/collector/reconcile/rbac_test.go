@@ -0,0 +1,115 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
+)
+
+func TestExpectedClusterRoles(t *testing.T) {
+	t.Run("should create the cluster role and binding", func(t *testing.T) {
+		err := ClusterRoles(context.Background(), params())
+		assert.NoError(t, err)
+		err = ClusterRoleBindings(context.Background(), params())
+		assert.NoError(t, err)
+
+		exists, err := populateObjectIfExists(t, &rbacv1.ClusterRole{}, types.NamespacedName{Name: "default.test-agent"})
+		assert.NoError(t, err)
+		assert.True(t, exists)
+
+		binding := rbacv1.ClusterRoleBinding{}
+		exists, err = populateObjectIfExists(t, &binding, types.NamespacedName{Name: "default.test-agent"})
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "test-agent", binding.Subjects[0].Name)
+	})
+
+	t.Run("should update the rules of an existing cluster role", func(t *testing.T) {
+		existing := collector.ClusterRole(logger, params().Instance, "gateway")
+		existing.Rules = nil
+		createObjectIfNotExists(t, existing.Name, &existing)
+
+		desired := collector.ClusterRole(logger, params().Instance, "gateway")
+		err := applyObjects(context.Background(), params(), []*rbacv1.ClusterRole{&desired})
+		assert.NoError(t, err)
+
+		actual := rbacv1.ClusterRole{}
+		_, err = populateObjectIfExists(t, &actual, types.NamespacedName{Name: existing.Name})
+		assert.NoError(t, err)
+		assert.Equal(t, collector.ClusterRole(logger, params().Instance, "gateway").Rules, actual.Rules)
+	})
+}
+
+func TestDeleteClusterRoles(t *testing.T) {
+	t.Run("should delete the cluster roles of disabled components", func(t *testing.T) {
+		existing := collector.ClusterRole(logger, params().Instance, "cluster-receiver")
+		createObjectIfNotExists(t, existing.Name, &existing)
+
+		desired := collector.ClusterRole(logger, params().Instance, "agent")
+		err := pruneObjects(context.Background(), params(), &rbacv1.ClusterRoleList{}, []*rbacv1.ClusterRole{&desired}, instanceLabels(params(), nil))
+		assert.NoError(t, err)
+
+		exists, err := populateObjectIfExists(t, &rbacv1.ClusterRole{}, types.NamespacedName{Name: existing.Name})
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestExpectedRoles(t *testing.T) {
+	t.Run("should create the role and binding of the components that need one", func(t *testing.T) {
+		p := params()
+		p.Instance.Spec.Agent.ConfigOverlay = `receivers:
+  smartagent/events:
+    type: kubernetes-events
+`
+		err := Roles(context.Background(), p)
+		assert.NoError(t, err)
+		err = RoleBindings(context.Background(), p)
+		assert.NoError(t, err)
+
+		role := rbacv1.Role{}
+		exists, err := populateObjectIfExists(t, &role, types.NamespacedName{Namespace: "default", Name: "test-agent"})
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, []string{"configmaps"}, role.Rules[0].Resources)
+
+		binding := rbacv1.RoleBinding{}
+		exists, err = populateObjectIfExists(t, &binding, types.NamespacedName{Namespace: "default", Name: "test-agent"})
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "test-agent", binding.Subjects[0].Name)
+	})
+
+	t.Run("should delete the role and binding once the config doesn't need them", func(t *testing.T) {
+		err := Roles(context.Background(), params())
+		assert.NoError(t, err)
+		err = RoleBindings(context.Background(), params())
+		assert.NoError(t, err)
+
+		exists, err := populateObjectIfExists(t, &rbacv1.Role{}, types.NamespacedName{Namespace: "default", Name: "test-agent"})
+		assert.NoError(t, err)
+		assert.False(t, exists)
+		exists, err = populateObjectIfExists(t, &rbacv1.RoleBinding{}, types.NamespacedName{Namespace: "default", Name: "test-agent"})
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
@@ -21,12 +21,10 @@ import (
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
-	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/adapters"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/naming"
@@ -36,54 +34,68 @@ import (
 
 // Services reconciles the service(s) required for the instance in the current context.
 func Services(ctx context.Context, params Params) error {
-	desired := []corev1.Service{}
+	desired, kept := []*corev1.Service{}, []*corev1.Service{}
 
-	if params.Instance.Spec.Gateway.Enabled != nil && *params.Instance.Spec.Gateway.Enabled {
-		type builder func(context.Context, Params) *corev1.Service
+	for _, kind := range v1alpha1.Components {
+		if params.Instance.ComponentPaused(kind) {
+			// only the names matter to keep the services from being deleted
+			for _, name := range []string{naming.Service(params.Instance, kind), naming.HeadlessService(params.Instance, kind), naming.MonitoringService(params.Instance, kind)} {
+				kept = append(kept, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: params.Instance.Namespace}})
+			}
+			continue
+		}
+		if !params.Instance.ServiceEnabled(kind) {
+			continue
+		}
+
+		type builder func(context.Context, Params, string) *corev1.Service
 		for _, builder := range []builder{desiredService, headless, monitoringService} {
-			// TODO(splunk): pass in params.Instance.Spec.Gateway instead of params
-			svc := builder(ctx, params)
+			svc := builder(ctx, params, kind)
 			// add only the non-nil to the list
 			if svc != nil {
-				desired = append(desired, *svc)
+				desired = append(desired, svc)
 			}
 		}
 	}
 
 	// first, handle the create/update parts
-	if err := expectedServices(ctx, params, desired); err != nil {
+	if err := applyObjects(ctx, params, desired); err != nil {
 		return fmt.Errorf("failed to reconcile the expected services: %w", err)
 	}
 
 	// then, delete the extra objects
-	if err := deleteServices(ctx, params, desired); err != nil {
+	if err := pruneObjects(ctx, params, &corev1.ServiceList{}, append(desired, kept...), client.InNamespace(params.Instance.Namespace), instanceLabels(params, nil)); err != nil {
 		return fmt.Errorf("failed to reconcile the services to be deleted: %w", err)
 	}
 
 	return nil
 }
 
-func desiredService(ctx context.Context, params Params) *corev1.Service {
+func desiredService(ctx context.Context, params Params, kind string) *corev1.Service {
+	spec := params.Instance.CollectorSpec(kind)
+
 	labels := collector.Labels(params.Instance)
-	labels["app.kubernetes.io/name"] = naming.Service(params.Instance)
+	labels["app.kubernetes.io/name"] = naming.Service(params.Instance, kind)
 
-	// by coincidence, the selector is the same as the label, but note that the selector points to the deployment
-	// whereas 'labels' refers to the service
-	selector := labels
+	configStr, err := adapters.ConfigWithOverlay(spec.Config, spec.ConfigOverlay)
+	if err != nil {
+		params.Log.Error(err, "couldn't merge the config overlay", "kind", kind)
+		return nil
+	}
 
-	config, err := adapters.ConfigFromString(params.Instance.Spec.Agent.Config)
+	config, err := adapters.ConfigFromString(configStr)
 	if err != nil {
-		params.Log.Error(err, "couldn't extract the configuration from the context")
+		params.Log.Error(err, "couldn't extract the configuration from the context", "kind", kind)
 		return nil
 	}
 
 	ports, err := adapters.ConfigToReceiverPorts(params.Log, config)
 	if err != nil {
-		params.Log.Error(err, "couldn't build the service for this instance")
+		params.Log.Error(err, "couldn't build the service for this instance", "kind", kind)
 		return nil
 	}
 
-	if len(params.Instance.Spec.Agent.Ports) > 0 {
+	if len(spec.Ports) > 0 {
 		// we should add all the ports from the CR
 		// there are two cases where problems might occur:
 		// 1) when the port number is already being used by a receiver
@@ -91,7 +103,8 @@ func desiredService(ctx context.Context, params Params) *corev1.Service {
 		//
 		// in the first case, we remove the port we inferred from the list
 		// in the second case, we rename our inferred port to something like "port-%d"
-		portNumbers, portNames := extractPortNumbersAndNames(params.Instance.Spec.Agent.Ports)
+		specPorts := namedPorts(spec.Ports)
+		portNumbers, portNames := extractPortNumbersAndNames(specPorts)
 		resultingInferredPorts := []corev1.ServicePort{}
 		for _, inferred := range ports {
 			if filtered := filterPort(params.Log, inferred, portNumbers, portNames); filtered != nil {
@@ -99,57 +112,63 @@ func desiredService(ctx context.Context, params Params) *corev1.Service {
 			}
 		}
 
-		ports = append(params.Instance.Spec.Agent.Ports, resultingInferredPorts...)
+		ports = append(specPorts, resultingInferredPorts...)
 	}
 
 	// if we have no ports, we don't need a service
 	if len(ports) == 0 {
-		params.Log.V(1).Info("the instance's configuration didn't yield any ports to open, skipping service", "instance.name", params.Instance.Name, "instance.namespace", params.Instance.Namespace)
+		params.Log.V(1).Info("the instance's configuration didn't yield any ports to open, skipping service", "instance.name", params.Instance.Name, "instance.namespace", params.Instance.Namespace, "kind", kind)
 		return nil
 	}
 
-	return &corev1.Service{
+	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        naming.Service(params.Instance),
+			Name:        naming.Service(params.Instance, kind),
 			Namespace:   params.Instance.Namespace,
 			Labels:      labels,
 			Annotations: params.Instance.Annotations,
 		},
 		Spec: corev1.ServiceSpec{
-			Selector:  selector,
+			Selector:  selector(params.Instance, kind),
 			ClusterIP: "",
 			Ports:     ports,
 		},
 	}
+
+	if kind == "agent" {
+		// there's an agent on every node, keep the traffic on the node of the client
+		local := corev1.ServiceInternalTrafficPolicyLocal
+		svc.Spec.InternalTrafficPolicy = &local
+	}
+
+	return svc
 }
 
-func headless(ctx context.Context, params Params) *corev1.Service {
-	h := desiredService(ctx, params)
+func headless(ctx context.Context, params Params, kind string) *corev1.Service {
+	h := desiredService(ctx, params, kind)
 	if h == nil {
 		return nil
 	}
 
-	h.Name = naming.HeadlessService(params.Instance)
+	h.Name = naming.HeadlessService(params.Instance, kind)
 	h.Spec.ClusterIP = "None"
+	h.Spec.InternalTrafficPolicy = nil
 	return h
 }
 
-func monitoringService(ctx context.Context, params Params) *corev1.Service {
+func monitoringService(ctx context.Context, params Params, kind string) *corev1.Service {
 	labels := collector.Labels(params.Instance)
-	labels["app.kubernetes.io/name"] = naming.MonitoringService(params.Instance)
-
-	selector := collector.Labels(params.Instance)
-	selector["app.kubernetes.io/name"] = fmt.Sprintf("%s-collector", params.Instance.Name)
+	labels["app.kubernetes.io/name"] = naming.MonitoringService(params.Instance, kind)
 
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        naming.MonitoringService(params.Instance),
+			Name:        naming.MonitoringService(params.Instance, kind),
 			Namespace:   params.Instance.Namespace,
 			Labels:      labels,
 			Annotations: params.Instance.Annotations,
 		},
 		Spec: corev1.ServiceSpec{
-			Selector:  selector,
+			Selector:  selector(params.Instance, kind),
 			ClusterIP: "",
 			Ports: []corev1.ServicePort{{
 				Name: "monitoring",
@@ -159,88 +178,11 @@ func monitoringService(ctx context.Context, params Params) *corev1.Service {
 	}
 }
 
-func expectedServices(ctx context.Context, params Params, expected []corev1.Service) error {
-	for _, obj := range expected {
-		desired := obj
-
-		if err := controllerutil.SetControllerReference(&params.Instance, &desired, params.Scheme); err != nil {
-			return fmt.Errorf("failed to set controller reference: %w", err)
-		}
-
-		existing := &corev1.Service{}
-		nns := types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}
-		err := params.Client.Get(ctx, nns, existing)
-		if err != nil && k8serrors.IsNotFound(err) {
-			if err = params.Client.Create(ctx, &desired); err != nil {
-				return fmt.Errorf("failed to create: %w", err)
-			}
-			params.Log.V(2).Info("created", "service.name", desired.Name, "service.namespace", desired.Namespace)
-			continue
-		} else if err != nil {
-			return fmt.Errorf("failed to get: %w", err)
-		}
-
-		// it exists already, merge the two if the end result isn't identical to the existing one
-		updated := existing.DeepCopy()
-		if updated.Annotations == nil {
-			updated.Annotations = map[string]string{}
-		}
-		if updated.Labels == nil {
-			updated.Labels = map[string]string{}
-		}
-		updated.ObjectMeta.OwnerReferences = desired.ObjectMeta.OwnerReferences
-
-		for k, v := range desired.ObjectMeta.Annotations {
-			updated.ObjectMeta.Annotations[k] = v
-		}
-		for k, v := range desired.ObjectMeta.Labels {
-			updated.ObjectMeta.Labels[k] = v
-		}
-		updated.Spec.Ports = desired.Spec.Ports
-
-		patch := client.MergeFrom(existing)
-
-		if err := params.Client.Patch(ctx, updated, patch); err != nil {
-			return fmt.Errorf("failed to apply changes: %w", err)
-		}
-
-		params.Log.V(2).Info("applied", "service.name", desired.Name, "service.namespace", desired.Namespace)
-	}
-
-	return nil
-}
-
-func deleteServices(ctx context.Context, params Params, expected []corev1.Service) error {
-	opts := []client.ListOption{
-		client.InNamespace(params.Instance.Namespace),
-		client.MatchingLabels(map[string]string{
-			"app.kubernetes.io/instance":   fmt.Sprintf("%s.%s", params.Instance.Namespace, params.Instance.Name),
-			"app.kubernetes.io/managed-by": "splunk-otel-collector-operator",
-		}),
-	}
-	list := &corev1.ServiceList{}
-	if err := params.Client.List(ctx, list, opts...); err != nil {
-		return fmt.Errorf("failed to list: %w", err)
-	}
-
-	for i := range list.Items {
-		existing := list.Items[i]
-		del := true
-		for _, keep := range expected {
-			if keep.Name == existing.Name && keep.Namespace == existing.Namespace {
-				del = false
-			}
-		}
-
-		if del {
-			if err := params.Client.Delete(ctx, &existing); err != nil {
-				return fmt.Errorf("failed to delete: %w", err)
-			}
-			params.Log.V(2).Info("deleted", "service.name", existing.Name, "service.namespace", existing.Namespace)
-		}
-	}
-
-	return nil
+// selector returns the labels of the pods of the given kind of collector.
+func selector(instance v1alpha1.Agent, kind string) map[string]string {
+	selector := collector.Labels(instance)
+	selector["app.kubernetes.io/name"] = fmt.Sprintf("%s-%s", instance.Name, kind)
+	return selector
 }
 
 func filterPort(logger logr.Logger, candidate corev1.ServicePort, portNumbers map[int32]bool, portNames map[string]bool) *corev1.ServicePort {
@@ -269,6 +211,29 @@ func filterPort(logger logr.Logger, candidate corev1.ServicePort, portNumbers ma
 	return &candidate
 }
 
+// namedPorts returns a copy of the given ports where the ports without a name are named "port-<number>": a Service
+// with more than one port is rejected when any of them is unnamed.
+func namedPorts(ports []corev1.ServicePort) []corev1.ServicePort {
+	names := map[string]bool{}
+	for _, port := range ports {
+		names[port.Name] = true
+	}
+
+	named := make([]corev1.ServicePort, len(ports))
+	for i, port := range ports {
+		if port.Name == "" {
+			name := fmt.Sprintf("port-%d", port.Port)
+			for n := 2; names[name]; n++ {
+				name = fmt.Sprintf("port-%d-%d", port.Port, n)
+			}
+			port.Name = name
+			names[name] = true
+		}
+		named[i] = port
+	}
+	return named
+}
+
 func extractPortNumbersAndNames(ports []corev1.ServicePort) (map[int32]bool, map[string]bool) {
 	numbers := map[int32]bool{}
 	names := map[string]bool{}
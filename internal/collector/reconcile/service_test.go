@@ -24,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
@@ -109,7 +110,7 @@ func TestDesiredService(t *testing.T) {
 				},
 			}}
 
-		actual := desiredService(context.Background(), params)
+		actual := desiredService(context.Background(), params, "gateway")
 		assert.Nil(t, actual)
 
 	})
@@ -124,7 +125,7 @@ func TestDesiredService(t *testing.T) {
 		}
 		ports := append(params().Instance.Spec.Gateway.Ports, jaegerPorts)
 		expected := service("test-collector", ports)
-		actual := desiredService(context.Background(), params())
+		actual := desiredService(context.Background(), params(), "gateway")
 
 		assert.Equal(t, expected, *actual)
 
@@ -132,10 +133,106 @@ func TestDesiredService(t *testing.T) {
 
 }
 
+func TestDesiredServicePerComponent(t *testing.T) {
+	t.Run("should build the gateway service from the gateway config", func(t *testing.T) {
+		p := params()
+		p.Instance.Spec.Gateway.Config = `receivers:
+  otlp:
+    protocols:
+      grpc:
+  zipkin:
+`
+		p.Instance.Spec.Gateway.Ports = nil
+
+		actual := desiredService(context.Background(), p, "gateway")
+
+		assert.Equal(t, "test-collector", actual.Name)
+		assert.Equal(t, "test-gateway", actual.Spec.Selector["app.kubernetes.io/name"])
+		assert.ElementsMatch(t, []string{"otlp-grpc", "zipkin"}, []string{actual.Spec.Ports[0].Name, actual.Spec.Ports[1].Name})
+		assert.Nil(t, actual.Spec.InternalTrafficPolicy)
+	})
+
+	t.Run("should keep the agent traffic on the node", func(t *testing.T) {
+		actual := desiredService(context.Background(), params(), "agent")
+
+		assert.Equal(t, "test-agent", actual.Name)
+		assert.Equal(t, "test-agent", actual.Spec.Selector["app.kubernetes.io/name"])
+		assert.Equal(t, "", actual.Spec.ClusterIP, "the agent service should get a cluster IP")
+		assert.Equal(t, v1.ServiceInternalTrafficPolicyLocal, *actual.Spec.InternalTrafficPolicy)
+	})
+
+	t.Run("should build each service from the ports of its component", func(t *testing.T) {
+		p := params()
+		p.Instance.Spec.Gateway.Config = `receivers:
+  otlp:
+    protocols:
+      grpc:
+`
+		agent := desiredService(context.Background(), p, "agent")
+		gateway := desiredService(context.Background(), p, "gateway")
+
+		assert.Contains(t, servicePortNames(agent), "web")
+		assert.NotContains(t, servicePortNames(gateway), "web")
+		assert.Contains(t, servicePortNames(gateway), "otlp")
+		assert.NotContains(t, servicePortNames(agent), "otlp")
+	})
+
+	t.Run("should name every port of the defaulted gateway", func(t *testing.T) {
+		p := params()
+		p.Instance = v1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+		p.Instance.Default()
+
+		actual := desiredService(context.Background(), p, "gateway")
+
+		names := map[string]bool{}
+		numbers := map[int32]bool{}
+		for _, port := range actual.Spec.Ports {
+			assert.NotEmpty(t, port.Name, "the port %d should be named", port.Port)
+			assert.False(t, names[port.Name], "the port name %q should be unique", port.Name)
+			assert.False(t, numbers[port.Port], "the port %d should be unique", port.Port)
+			names[port.Name] = true
+			numbers[port.Port] = true
+		}
+		assert.True(t, numbers[55681])
+	})
+
+	t.Run("should name the ports set without a name", func(t *testing.T) {
+		p := params()
+		p.Instance.Spec.Gateway.Config = `receivers:
+  zipkin:
+`
+		p.Instance.Spec.Gateway.Ports = []v1.ServicePort{{Name: "otlp", Port: 4317}, {Port: 55681}}
+
+		actual := desiredService(context.Background(), p, "gateway")
+
+		assert.Contains(t, servicePortNames(actual), "port-55681")
+		assert.NotContains(t, servicePortNames(actual), "")
+		assert.Empty(t, p.Instance.Spec.Gateway.Ports[1].Name, "the spec shouldn't be changed")
+	})
+}
+
+func TestNamedPorts(t *testing.T) {
+	ports := []v1.ServicePort{{Name: "web", Port: 80}, {Port: 8080}, {Name: "port-9090", Port: 443}, {Port: 9090}}
+
+	actual := namedPorts(ports)
+
+	assert.Equal(t, []string{"web", "port-8080", "port-9090", "port-9090-2"}, servicePortNames(&v1.Service{Spec: v1.ServiceSpec{Ports: actual}}))
+	assert.Empty(t, ports[1].Name, "the given ports shouldn't be changed")
+}
+
+func servicePortNames(svc *v1.Service) []string {
+	var names []string
+	for _, port := range svc.Spec.Ports {
+		names = append(names, port.Name)
+	}
+	return names
+}
+
 func TestExpectedServices(t *testing.T) {
 	t.Skip("not needed now. will be enabled once we support gateway")
 	t.Run("should create the service", func(t *testing.T) {
-		err := expectedServices(context.Background(), params(), []v1.Service{service("test-collector", params().Instance.Spec.Gateway.Ports)})
+		desired := service("test-collector", params().Instance.Spec.Gateway.Ports)
+		err := applyObjects(context.Background(), params(), []*v1.Service{&desired})
 		assert.NoError(t, err)
 
 		exists, err := populateObjectIfExists(t, &v1.Service{}, types.NamespacedName{Namespace: "default", Name: "test-collector"})
@@ -156,7 +253,8 @@ func TestExpectedServices(t *testing.T) {
 		}
 
 		ports := append(params().Instance.Spec.Gateway.Ports, extraPorts)
-		err := expectedServices(context.Background(), params(), []v1.Service{service("test-collector", ports)})
+		desired := service("test-collector", ports)
+		err := applyObjects(context.Background(), params(), []*v1.Service{&desired})
 		assert.NoError(t, err)
 
 		actual := v1.Service{}
@@ -183,8 +281,8 @@ func TestDeleteServices(t *testing.T) {
 		assert.NoError(t, err)
 		assert.True(t, exists)
 
-		desired := desiredService(context.Background(), params())
-		err = deleteServices(context.Background(), params(), []v1.Service{*desired})
+		desired := desiredService(context.Background(), params(), "agent")
+		err = pruneObjects(context.Background(), params(), &v1.ServiceList{}, []*v1.Service{desired}, client.InNamespace("default"), instanceLabels(params(), nil))
 		assert.NoError(t, err)
 
 		exists, err = populateObjectIfExists(t, &v1.Service{}, types.NamespacedName{Namespace: "default", Name: "delete-service-collector"})
@@ -196,7 +294,7 @@ func TestDeleteServices(t *testing.T) {
 
 func TestHeadlessService(t *testing.T) {
 	t.Run("should return headless service", func(t *testing.T) {
-		actual := headless(context.Background(), params())
+		actual := headless(context.Background(), params(), "agent")
 		assert.Equal(t, actual.Spec.ClusterIP, "None")
 	})
 }
@@ -207,7 +305,7 @@ func TestMonitoringService(t *testing.T) {
 			Name: "monitoring",
 			Port: 8888,
 		}}
-		actual := monitoringService(context.Background(), params())
+		actual := monitoringService(context.Background(), params(), "gateway")
 		assert.Equal(t, expected, actual.Spec.Ports)
 
 	})
@@ -23,17 +23,18 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
 )
 
 func TestExpectedServiceAccounts(t *testing.T) {
 	t.Run("should create service account", func(t *testing.T) {
-		desired := collector.ServiceAccount(params().Instance)
-		err := expectedServiceAccounts(context.Background(), params(), []v1.ServiceAccount{desired})
+		desired := collector.ServiceAccount(params().Instance, "agent")
+		err := applyObjects(context.Background(), params(), []*v1.ServiceAccount{&desired})
 		assert.NoError(t, err)
 
-		exists, err := populateObjectIfExists(t, &v1.ServiceAccount{}, types.NamespacedName{Namespace: "default", Name: "splunk-otel-operator-account"})
+		exists, err := populateObjectIfExists(t, &v1.ServiceAccount{}, types.NamespacedName{Namespace: "default", Name: "test-agent"})
 		assert.NoError(t, err)
 		assert.True(t, exists)
 	})
@@ -41,20 +42,21 @@ func TestExpectedServiceAccounts(t *testing.T) {
 	t.Run("should update existing service account", func(t *testing.T) {
 		existing := v1.ServiceAccount{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      "splunk-otel-operator-account",
+				Name:      "test-agent",
 				Namespace: "default",
 			},
 		}
-		createObjectIfNotExists(t, "splunk-otel-operator-account", &existing)
-		exists, err := populateObjectIfExists(t, &v1.ServiceAccount{}, types.NamespacedName{Namespace: "default", Name: "splunk-otel-operator-account"})
+		createObjectIfNotExists(t, "test-agent", &existing)
+		exists, err := populateObjectIfExists(t, &v1.ServiceAccount{}, types.NamespacedName{Namespace: "default", Name: "test-agent"})
 		assert.NoError(t, err)
 		assert.True(t, exists)
 
-		err = expectedServiceAccounts(context.Background(), params(), []v1.ServiceAccount{collector.ServiceAccount(params().Instance)})
+		desired := collector.ServiceAccount(params().Instance, "agent")
+		err = applyObjects(context.Background(), params(), []*v1.ServiceAccount{&desired})
 		assert.NoError(t, err)
 
 		actual := v1.ServiceAccount{}
-		_, err = populateObjectIfExists(t, &actual, types.NamespacedName{Namespace: "default", Name: "splunk-otel-operator-account"})
+		_, err = populateObjectIfExists(t, &actual, types.NamespacedName{Namespace: "default", Name: "test-agent"})
 		assert.NoError(t, err)
 	})
 }
@@ -76,7 +78,8 @@ func TestDeleteServiceAccounts(t *testing.T) {
 		assert.NoError(t, err)
 		assert.True(t, exists)
 
-		err = deleteServiceAccounts(context.Background(), params(), []v1.ServiceAccount{collector.ServiceAccount(params().Instance)})
+		desired := collector.ServiceAccount(params().Instance, "agent")
+		err = pruneObjects(context.Background(), params(), &v1.ServiceAccountList{}, []*v1.ServiceAccount{&desired}, client.InNamespace("default"), instanceLabels(params(), nil))
 		assert.NoError(t, err)
 
 		exists, err = populateObjectIfExists(t, &v1.ServiceAccount{}, types.NamespacedName{Namespace: "default", Name: "test-delete-collector"})
@@ -100,7 +103,8 @@ func TestDeleteServiceAccounts(t *testing.T) {
 		assert.NoError(t, err)
 		assert.True(t, exists)
 
-		err = deleteServiceAccounts(context.Background(), params(), []v1.ServiceAccount{collector.ServiceAccount(params().Instance)})
+		desired := collector.ServiceAccount(params().Instance, "agent")
+		err = pruneObjects(context.Background(), params(), &v1.ServiceAccountList{}, []*v1.ServiceAccount{&desired}, client.InNamespace("default"), instanceLabels(params(), nil))
 		assert.NoError(t, err)
 
 		exists, err = populateObjectIfExists(t, &v1.ServiceAccount{}, types.NamespacedName{Namespace: "default", Name: "test-delete-collector"})
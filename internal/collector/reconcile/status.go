@@ -0,0 +1,288 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/naming"
+)
+
+// accessTokenEnvVar is the env var the collector configs read the Splunk access token from.
+const accessTokenEnvVar = "SPLUNK_ACCESS_TOKEN"
+
+// UpdateStatus reports the outcome of the reconciliation in the status of the instance: the pod counts of each enabled
+// component and the conditions derived from them, the configs and the tasks that failed, if any. It should be called
+// once all the tasks ran, whether they succeeded or not.
+func UpdateStatus(ctx context.Context, params Params, failures []TaskFailure) error {
+	changed := params.Instance.DeepCopy()
+	status := &changed.Status
+
+	components, missing, err := componentStatuses(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to get the status of the components: %w", err)
+	}
+	status.Components = components
+
+	tokenCondition, err := accessTokenCondition(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to check the access token: %w", err)
+	}
+	tokenMissingCondition, err := accessTokenMissingCondition(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to check the synced access token: %w", err)
+	}
+
+	generation := params.Instance.Generation
+	if params.Instance.Paused() {
+		// the current spec isn't applied while paused
+		generation = params.Instance.Status.ObservedGeneration
+	}
+	for _, condition := range []metav1.Condition{
+		pausedCondition(params.Instance),
+		configValidCondition(params.Instance),
+		tokenCondition,
+		tokenMissingCondition,
+		degradedCondition(failures),
+		progressingCondition(components),
+		readyCondition(components, missing, failures, tokenMissingCondition),
+	} {
+		condition.ObservedGeneration = generation
+		meta.SetStatusCondition(&status.Conditions, condition)
+	}
+	status.ObservedGeneration = generation
+
+	patch := client.MergeFrom(&params.Instance)
+	if err := params.Client.Status().Patch(ctx, changed, patch); err != nil {
+		return fmt.Errorf("failed to apply status changes to the OpenTelemetry CR: %w", err)
+	}
+
+	return nil
+}
+
+// componentStatuses returns the pod counts of the workloads of the enabled components, along with the components whose
+// workload doesn't exist. Those are reported without any pod.
+func componentStatuses(ctx context.Context, params Params) ([]v1alpha1.ComponentStatus, []string, error) {
+	var statuses []v1alpha1.ComponentStatus
+	var missing []string
+	for _, component := range v1alpha1.Components {
+		if !params.Instance.ComponentEnabled(component) {
+			continue
+		}
+
+		status := v1alpha1.ComponentStatus{Name: component}
+		var err error
+		switch component {
+		case "agent":
+			ds := &appsv1.DaemonSet{}
+			if err = getWorkload(ctx, params, naming.Agent(params.Instance), ds); err == nil {
+				status.Desired = ds.Status.DesiredNumberScheduled
+				status.Ready = ds.Status.NumberReady
+				status.Updated = ds.Status.UpdatedNumberScheduled
+				if ds.Status.ObservedGeneration < ds.Generation {
+					// the counts are about the previous spec until the daemon set controller catches up
+					status.Updated = 0
+				}
+			}
+		default:
+			name := naming.ClusterReceiver(params.Instance)
+			if component == "gateway" {
+				name = naming.Gateway(params.Instance)
+			}
+			deployment := &appsv1.Deployment{}
+			if err = getWorkload(ctx, params, name, deployment); err == nil {
+				status.Desired = 1
+				if deployment.Spec.Replicas != nil {
+					status.Desired = *deployment.Spec.Replicas
+				}
+				status.Ready = deployment.Status.ReadyReplicas
+				status.Updated = deployment.Status.UpdatedReplicas
+				if deployment.Status.ObservedGeneration < deployment.Generation {
+					status.Updated = 0
+				}
+			}
+		}
+
+		if k8serrors.IsNotFound(err) {
+			missing = append(missing, component)
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("failed to get the %s workload: %w", component, err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, missing, nil
+}
+
+func getWorkload(ctx context.Context, params Params, name string, obj client.Object) error {
+	return params.Client.Get(ctx, types.NamespacedName{Namespace: params.Instance.Namespace, Name: name}, obj)
+}
+
+func pausedCondition(instance v1alpha1.Agent) metav1.Condition {
+	if instance.Paused() {
+		return metav1.Condition{Type: v1alpha1.ConditionPaused, Status: metav1.ConditionTrue, Reason: "Paused",
+			Message: fmt.Sprintf("reconciliation paused by the %s annotation", v1alpha1.AnnotationReconcile)}
+	}
+
+	var paused []string
+	for _, component := range v1alpha1.Components {
+		if instance.ComponentPaused(component) {
+			paused = append(paused, component)
+		}
+	}
+	if len(paused) > 0 {
+		return metav1.Condition{Type: v1alpha1.ConditionPaused, Status: metav1.ConditionTrue, Reason: "ComponentsPaused",
+			Message: fmt.Sprintf("reconciliation paused for the %s", strings.Join(paused, ", "))}
+	}
+	return metav1.Condition{Type: v1alpha1.ConditionPaused, Status: metav1.ConditionFalse, Reason: "Reconciling"}
+}
+
+func configValidCondition(instance v1alpha1.Agent) metav1.Condition {
+	if err := instance.ValidateConfigs(); err != nil {
+		return metav1.Condition{Type: v1alpha1.ConditionConfigValid, Status: metav1.ConditionFalse, Reason: "InvalidConfig", Message: err.Error()}
+	}
+	return metav1.Condition{Type: v1alpha1.ConditionConfigValid, Status: metav1.ConditionTrue, Reason: "Valid"}
+}
+
+// accessTokenCondition checks that the secrets the enabled components read the access token from exist and hold the
+// referenced key.
+func accessTokenCondition(ctx context.Context, params Params) (metav1.Condition, error) {
+	var missing []string
+	checked := map[string]bool{}
+	for _, component := range v1alpha1.Components {
+		if !params.Instance.ComponentEnabled(component) {
+			continue
+		}
+
+		for _, env := range params.Instance.CollectorSpec(component).Env {
+			if env.Name != accessTokenEnvVar || env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil {
+				continue
+			}
+			ref := env.ValueFrom.SecretKeyRef
+			if checked[ref.Name+"/"+ref.Key] || ref.Optional != nil && *ref.Optional {
+				continue
+			}
+			checked[ref.Name+"/"+ref.Key] = true
+
+			secret := &corev1.Secret{}
+			err := params.Client.Get(ctx, types.NamespacedName{Namespace: params.Instance.Namespace, Name: ref.Name}, secret)
+			if k8serrors.IsNotFound(err) {
+				missing = append(missing, fmt.Sprintf("secret %q not found", ref.Name))
+				continue
+			} else if err != nil {
+				return metav1.Condition{}, fmt.Errorf("failed to get the secret %q: %w", ref.Name, err)
+			}
+			if _, ok := secret.Data[ref.Key]; !ok {
+				missing = append(missing, fmt.Sprintf("key %q not found in the secret %q", ref.Key, ref.Name))
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return metav1.Condition{Type: v1alpha1.ConditionAccessTokenFound, Status: metav1.ConditionFalse, Reason: "SecretNotFound",
+			Message: strings.Join(missing, "; ")}, nil
+	}
+	return metav1.Condition{Type: v1alpha1.ConditionAccessTokenFound, Status: metav1.ConditionTrue, Reason: "SecretFound"}, nil
+}
+
+// accessTokenMissingCondition reports whether the collectors wait for the access token the operator syncs from the
+// secret referenced by the spec.
+func accessTokenMissingCondition(ctx context.Context, params Params) (metav1.Condition, error) {
+	source, key, ok := collector.AccessTokenSource(params.Instance)
+	if !ok {
+		return metav1.Condition{Type: v1alpha1.ConditionAccessTokenMissing, Status: metav1.ConditionFalse, Reason: "Unmanaged"}, nil
+	}
+
+	pending, err := accessTokenPending(ctx, params)
+	if err != nil {
+		return metav1.Condition{}, err
+	}
+	if pending {
+		return metav1.Condition{Type: v1alpha1.ConditionAccessTokenMissing, Status: metav1.ConditionTrue, Reason: "SourceNotFound",
+			Message: fmt.Sprintf("waiting for the key %q of the secret %q", key, source.String())}, nil
+	}
+	return metav1.Condition{Type: v1alpha1.ConditionAccessTokenMissing, Status: metav1.ConditionFalse, Reason: "Synced"}, nil
+}
+
+func degradedCondition(failures []TaskFailure) metav1.Condition {
+	if len(failures) > 0 {
+		return metav1.Condition{Type: v1alpha1.ConditionDegraded, Status: metav1.ConditionTrue, Reason: failures[0].Reason(), Message: failureMessage(failures)}
+	}
+	return metav1.Condition{Type: v1alpha1.ConditionDegraded, Status: metav1.ConditionFalse, Reason: "AsExpected"}
+}
+
+// failureMessage lists the failed tasks, the reason of the conditions only holds the first one.
+func failureMessage(failures []TaskFailure) string {
+	messages := make([]string, 0, len(failures))
+	for _, f := range failures {
+		messages = append(messages, f.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+func progressingCondition(components []v1alpha1.ComponentStatus) metav1.Condition {
+	var rolling []string
+	for _, c := range components {
+		if c.Updated < c.Desired {
+			rolling = append(rolling, fmt.Sprintf("%s: %d/%d pods updated", c.Name, c.Updated, c.Desired))
+		}
+	}
+
+	if len(rolling) > 0 {
+		return metav1.Condition{Type: v1alpha1.ConditionProgressing, Status: metav1.ConditionTrue, Reason: "RollingOut", Message: strings.Join(rolling, ", ")}
+	}
+	return metav1.Condition{Type: v1alpha1.ConditionProgressing, Status: metav1.ConditionFalse, Reason: "RolledOut"}
+}
+
+// readyCondition reports whether the workloads of the enabled components exist and run their current spec on every
+// pod. A missing workload isn't ready, even though it doesn't desire any pod.
+func readyCondition(components []v1alpha1.ComponentStatus, missing []string, failures []TaskFailure, tokenMissing metav1.Condition) metav1.Condition {
+	if len(failures) > 0 {
+		return metav1.Condition{Type: v1alpha1.ConditionReady, Status: metav1.ConditionFalse, Reason: failures[0].Reason(), Message: failureMessage(failures)}
+	}
+	if tokenMissing.Status == metav1.ConditionTrue {
+		// the workloads aren't deployed yet
+		return metav1.Condition{Type: v1alpha1.ConditionReady, Status: metav1.ConditionFalse, Reason: v1alpha1.ConditionAccessTokenMissing, Message: tokenMissing.Message}
+	}
+	if len(missing) > 0 {
+		return metav1.Condition{Type: v1alpha1.ConditionReady, Status: metav1.ConditionFalse, Reason: "WorkloadMissing",
+			Message: fmt.Sprintf("workload not found for the %s", strings.Join(missing, ", "))}
+	}
+
+	var notReady []string
+	for _, c := range components {
+		if c.Ready < c.Desired || c.Updated < c.Desired {
+			notReady = append(notReady, fmt.Sprintf("%s: %d/%d pods ready", c.Name, c.Ready, c.Desired))
+		}
+	}
+
+	if len(notReady) > 0 {
+		return metav1.Condition{Type: v1alpha1.ConditionReady, Status: metav1.ConditionFalse, Reason: "ComponentsNotReady", Message: strings.Join(notReady, ", ")}
+	}
+	return metav1.Condition{Type: v1alpha1.ConditionReady, Status: metav1.ConditionTrue, Reason: "ComponentsReady"}
+}
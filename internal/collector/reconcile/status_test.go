@@ -0,0 +1,204 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+)
+
+func TestReadyCondition(t *testing.T) {
+	for _, tt := range []struct {
+		desc       string
+		components []v1alpha1.ComponentStatus
+		missing    []string
+		failures   []TaskFailure
+		token      metav1.ConditionStatus
+		status     metav1.ConditionStatus
+		reason     string
+		message    string
+	}{
+		{
+			desc: "all pods ready",
+			components: []v1alpha1.ComponentStatus{
+				{Name: "agent", Desired: 3, Ready: 3, Updated: 3},
+				{Name: "cluster-receiver", Desired: 1, Ready: 1, Updated: 1},
+			},
+			status: metav1.ConditionTrue,
+			reason: "ComponentsReady",
+		},
+		{
+			desc: "pods not ready",
+			components: []v1alpha1.ComponentStatus{
+				{Name: "agent", Desired: 3, Ready: 2, Updated: 3},
+				{Name: "gateway", Desired: 2, Ready: 2, Updated: 1},
+			},
+			status:  metav1.ConditionFalse,
+			reason:  "ComponentsNotReady",
+			message: "agent: 2/3 pods ready, gateway: 2/2 pods ready",
+		},
+		{
+			desc: "workloads missing",
+			components: []v1alpha1.ComponentStatus{
+				{Name: "agent", Desired: 3, Ready: 3, Updated: 3},
+				{Name: "cluster-receiver"},
+				{Name: "gateway"},
+			},
+			missing: []string{"cluster-receiver", "gateway"},
+			status:  metav1.ConditionFalse,
+			reason:  "WorkloadMissing",
+			message: "workload not found for the cluster-receiver, gateway",
+		},
+		{
+			desc: "tasks failed",
+			failures: []TaskFailure{
+				{Task: "services", Err: errors.New("failed to create: forbidden")},
+				{Task: "cluster receiver", Err: errors.New("failed to get: timeout")},
+			},
+			status:  metav1.ConditionFalse,
+			reason:  "ServicesFailed",
+			message: "failed to reconcile services: failed to create: forbidden; failed to reconcile cluster receiver: failed to get: timeout",
+		},
+		{
+			desc:    "access token missing",
+			token:   metav1.ConditionTrue,
+			status:  metav1.ConditionFalse,
+			reason:  "AccessTokenMissing",
+			message: "waiting for the token",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			// test
+			tokenMissing := metav1.Condition{Type: v1alpha1.ConditionAccessTokenMissing, Status: metav1.ConditionFalse}
+			if tt.token != "" {
+				tokenMissing = metav1.Condition{Type: v1alpha1.ConditionAccessTokenMissing, Status: tt.token, Message: "waiting for the token"}
+			}
+			condition := readyCondition(tt.components, tt.missing, tt.failures, tokenMissing)
+
+			// verify
+			assert.Equal(t, v1alpha1.ConditionReady, condition.Type)
+			assert.Equal(t, tt.status, condition.Status)
+			assert.Equal(t, tt.reason, condition.Reason)
+			assert.Equal(t, tt.message, condition.Message)
+		})
+	}
+}
+
+func TestTaskFailureReason(t *testing.T) {
+	assert.Equal(t, "ConfigMapsFailed", TaskFailure{Task: "config maps"}.Reason())
+	assert.Equal(t, "AgentFailed", TaskFailure{Task: "agent"}.Reason())
+}
+
+func TestProgressingCondition(t *testing.T) {
+	rolling := progressingCondition([]v1alpha1.ComponentStatus{{Name: "agent", Desired: 3, Ready: 3, Updated: 1}})
+	assert.Equal(t, metav1.ConditionTrue, rolling.Status)
+	assert.Equal(t, "agent: 1/3 pods updated", rolling.Message)
+
+	done := progressingCondition([]v1alpha1.ComponentStatus{{Name: "agent", Desired: 3, Ready: 2, Updated: 3}})
+	assert.Equal(t, metav1.ConditionFalse, done.Status)
+}
+
+func TestConfigValidCondition(t *testing.T) {
+	instance := params().Instance
+	instance.Spec.Gateway.Config = "receivers:\n  otlp:\n"
+
+	condition := configValidCondition(instance)
+
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "InvalidConfig", condition.Reason)
+	assert.Contains(t, condition.Message, "gateway")
+}
+
+func TestPausedCondition(t *testing.T) {
+	instance := params().Instance
+
+	condition := pausedCondition(instance)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+
+	instance.Annotations = map[string]string{"otel.splunk.com/reconcile-gateway": "paused", "otel.splunk.com/reconcile-agent": "paused"}
+	condition = pausedCondition(instance)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "ComponentsPaused", condition.Reason)
+	assert.Equal(t, "reconciliation paused for the agent, gateway", condition.Message)
+
+	instance.Annotations = map[string]string{"otel.splunk.com/reconcile": "paused"}
+	condition = pausedCondition(instance)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "Paused", condition.Reason)
+}
+
+func TestUpdateStatus(t *testing.T) {
+	t.Run("should report the components and conditions", func(t *testing.T) {
+		instance := params().Instance
+		instance.Name = "test-status"
+		createObjectIfNotExists(t, instance.Name, &instance)
+		param := params()
+		param.Instance = instance
+		param.Instance.Spec.Agent.Env = []corev1.EnvVar{{
+			Name: accessTokenEnvVar,
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "test-status-token"},
+				Key:                  "access-token",
+			}},
+		}}
+
+		err := UpdateStatus(context.Background(), param, []TaskFailure{{Task: "agent", Err: errors.New("failed to create: forbidden")}})
+		require.NoError(t, err)
+
+		actual := v1alpha1.Agent{}
+		exists, err := populateObjectIfExists(t, &actual, types.NamespacedName{Namespace: "default", Name: "test-status"})
+		require.NoError(t, err)
+		require.True(t, exists)
+
+		assert.Equal(t, actual.Generation, actual.Status.ObservedGeneration)
+		assert.True(t, meta.IsStatusConditionTrue(actual.Status.Conditions, v1alpha1.ConditionDegraded))
+		assert.Equal(t, "AgentFailed", meta.FindStatusCondition(actual.Status.Conditions, v1alpha1.ConditionDegraded).Reason)
+		assert.True(t, meta.IsStatusConditionFalse(actual.Status.Conditions, v1alpha1.ConditionReady))
+		assert.True(t, meta.IsStatusConditionFalse(actual.Status.Conditions, v1alpha1.ConditionAccessTokenFound))
+		assert.Equal(t, `secret "test-status-token" not found`, meta.FindStatusCondition(actual.Status.Conditions, v1alpha1.ConditionAccessTokenFound).Message)
+		assert.Equal(t, "agent", actual.Status.Components[0].Name)
+	})
+
+	t.Run("should not report missing workloads as ready", func(t *testing.T) {
+		instance := params().Instance
+		instance.Name = "test-status-missing"
+		createObjectIfNotExists(t, instance.Name, &instance)
+		param := params()
+		param.Instance = instance
+
+		err := UpdateStatus(context.Background(), param, nil)
+		require.NoError(t, err)
+
+		actual := v1alpha1.Agent{}
+		exists, err := populateObjectIfExists(t, &actual, types.NamespacedName{Namespace: "default", Name: "test-status-missing"})
+		require.NoError(t, err)
+		require.True(t, exists)
+
+		ready := meta.FindStatusCondition(actual.Status.Conditions, v1alpha1.ConditionReady)
+		require.NotNil(t, ready)
+		assert.Equal(t, metav1.ConditionFalse, ready.Status)
+		assert.Equal(t, "WorkloadMissing", ready.Reason)
+	})
+}
@@ -23,23 +23,24 @@ import (
 	"github.com/signalfx/splunk-otel-collector-operator/internal/naming"
 )
 
-// ServiceAccountName returns the name of the existing or self-provisioned service account to use for the given instance.
-func ServiceAccountName(instance v1alpha1.Agent) string {
-	if len(instance.Spec.Agent.ServiceAccount) == 0 {
-		return naming.ServiceAccount(instance)
+// ServiceAccountName returns the name of the existing or self-provisioned service account to use for the given
+// component of the instance.
+func ServiceAccountName(instance v1alpha1.Agent, component string) string {
+	if spec := instance.CollectorSpec(component); spec != nil && len(spec.ServiceAccount) > 0 {
+		return spec.ServiceAccount
 	}
 
-	return instance.Spec.Agent.ServiceAccount
+	return naming.ServiceAccount(instance, component)
 }
 
-// ServiceAccount returns the service account for the given instance.
-func ServiceAccount(otelcol v1alpha1.Agent) corev1.ServiceAccount {
+// ServiceAccount returns the service account for the given component of the instance.
+func ServiceAccount(otelcol v1alpha1.Agent, component string) corev1.ServiceAccount {
 	labels := Labels(otelcol)
-	labels["app.kubernetes.io/name"] = naming.ServiceAccount(otelcol)
+	labels["app.kubernetes.io/name"] = naming.ServiceAccount(otelcol, component)
 
 	return corev1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        naming.ServiceAccount(otelcol),
+			Name:        naming.ServiceAccount(otelcol, component),
 			Namespace:   otelcol.Namespace,
 			Labels:      labels,
 			Annotations: otelcol.Annotations,
@@ -34,10 +34,11 @@ func TestServiceAccountNewDefault(t *testing.T) {
 	}
 
 	// test
-	sa := ServiceAccountName(otelcol)
+	sa := ServiceAccountName(otelcol, "agent")
 
 	// verify
-	assert.Equal(t, "splunk-otel-operator-account", sa)
+	assert.Equal(t, "my-instance-agent", sa)
+	assert.Equal(t, "my-instance-gateway", ServiceAccountName(otelcol, "gateway"))
 }
 
 func TestServiceAccountOverride(t *testing.T) {
@@ -52,8 +53,9 @@ func TestServiceAccountOverride(t *testing.T) {
 	}
 
 	// test
-	sa := ServiceAccountName(otelcol)
+	sa := ServiceAccountName(otelcol, "agent")
 
 	// verify
 	assert.Equal(t, "my-special-sa", sa)
+	assert.Equal(t, "my-instance-cluster-receiver", ServiceAccountName(otelcol, "cluster-receiver"))
 }
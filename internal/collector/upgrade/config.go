@@ -0,0 +1,186 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/adapters"
+)
+
+// configMigration rewrites the parsed collector configuration in place, returning a message for each change it made.
+type configMigration func(cfg map[interface{}]interface{}) []string
+
+// migrateConfig runs the migration on the spec's config and config overlay, marshalling the results back into the
+// spec. The overlay is migrated on its own, as it's written against the same collector version as the config.
+// Each of them is left untouched when the migration didn't change anything.
+func migrateConfig(spec *v1alpha1.CollectorSpec, ver string, migration configMigration) ([]string, error) {
+	config, messages, err := migrateYAML(spec.Config, "configuration", ver, migration)
+	if err != nil {
+		return nil, err
+	}
+
+	overlay, overlayMessages, err := migrateYAML(spec.ConfigOverlay, "configuration overlay", ver, migration)
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range overlayMessages {
+		messages = append(messages, msg+" in the config overlay")
+	}
+
+	spec.Config = config
+	spec.ConfigOverlay = overlay
+	return messages, nil
+}
+
+// migrateYAML parses the raw config, runs the migration on it and marshals the result back, returning the raw config
+// as is when the migration didn't change anything.
+func migrateYAML(raw, kind, ver string, migration configMigration) (string, []string, error) {
+	if len(raw) == 0 {
+		return raw, nil, nil
+	}
+
+	cfg, err := adapters.ConfigFromString(raw)
+	if err != nil {
+		return raw, nil, fmt.Errorf("couldn't upgrade to v%s, failed to parse %s: %w", ver, kind, err)
+	}
+
+	messages := migration(cfg)
+	if len(messages) == 0 {
+		return raw, nil, nil
+	}
+
+	res, err := yaml.Marshal(cfg)
+	if err != nil {
+		return raw, nil, fmt.Errorf("couldn't upgrade to v%s, failed to marshall back %s: %w", ver, kind, err)
+	}
+	return string(res), messages, nil
+}
+
+// componentsOfType returns the names of the components of the given type in a config section, like the "logging"
+// and "logging/debug" exporters for the "logging" type. Names are sorted so that messages are stable.
+func componentsOfType(cfg map[interface{}]interface{}, section, componentType string) []string {
+	components, ok := cfg[section].(map[interface{}]interface{})
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	for k := range components {
+		name, ok := k.(string)
+		if !ok {
+			continue
+		}
+		if name == componentType || strings.HasPrefix(name, componentType+"/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// componentConfig returns the settings of the named component, creating an empty map for components declared
+// without settings, like "batch: null".
+func componentConfig(cfg map[interface{}]interface{}, section, name string) map[interface{}]interface{} {
+	components, ok := cfg[section].(map[interface{}]interface{})
+	if !ok {
+		return nil
+	}
+
+	settings, ok := components[name].(map[interface{}]interface{})
+	if !ok {
+		settings = map[interface{}]interface{}{}
+		components[name] = settings
+	}
+	return settings
+}
+
+// renameComponent renames a component in its section as well as everywhere it's referenced by the service.
+func renameComponent(cfg map[interface{}]interface{}, section, from, to string) error {
+	components, ok := cfg[section].(map[interface{}]interface{})
+	if !ok {
+		return nil
+	}
+
+	if _, exists := components[to]; exists {
+		return fmt.Errorf("the %s %q is already defined", section, to)
+	}
+
+	components[to] = components[from]
+	delete(components, from)
+
+	forEachServiceReference(cfg, section, func(refs []interface{}) []interface{} {
+		for i, ref := range refs {
+			if ref == from {
+				refs[i] = to
+			}
+		}
+		return refs
+	})
+	return nil
+}
+
+// removeComponent removes a component from its section as well as everywhere it's referenced by the service.
+func removeComponent(cfg map[interface{}]interface{}, section, name string) {
+	if components, ok := cfg[section].(map[interface{}]interface{}); ok {
+		delete(components, name)
+	}
+
+	forEachServiceReference(cfg, section, func(refs []interface{}) []interface{} {
+		kept := []interface{}{}
+		for _, ref := range refs {
+			if ref != name {
+				kept = append(kept, ref)
+			}
+		}
+		return kept
+	})
+}
+
+// forEachServiceReference calls fn for every list in the service referencing components of the given section:
+// service.extensions for extensions, and the matching list of each pipeline for the other sections.
+func forEachServiceReference(cfg map[interface{}]interface{}, section string, fn func([]interface{}) []interface{}) {
+	service, ok := cfg["service"].(map[interface{}]interface{})
+	if !ok {
+		return
+	}
+
+	if section == "extensions" {
+		if refs, ok := service["extensions"].([]interface{}); ok {
+			service["extensions"] = fn(refs)
+		}
+		return
+	}
+
+	pipelines, ok := service["pipelines"].(map[interface{}]interface{})
+	if !ok {
+		return
+	}
+
+	for _, p := range pipelines {
+		pipeline, ok := p.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+		if refs, ok := pipeline[section].([]interface{}); ok {
+			pipeline[section] = fn(refs)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/adapters"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/naming"
+)
+
+// AnnotationDryRun makes the upgrade routine publish a report of the pending changes instead of applying them,
+// when set to "true" on an instance.
+const AnnotationDryRun = "otel.splunk.com/upgrade-dry-run"
+
+// DryRun reports whether the upgrades for the given instance should only be reported.
+func DryRun(otelcol v1alpha1.Agent) bool {
+	return strings.EqualFold(otelcol.Annotations[AnnotationDryRun], "true")
+}
+
+// publishDryRunReport stores the report for the given upgrade in a config map next to the instance and records an
+// event pointing to it. The instance itself is left untouched.
+func publishDryRunReport(ctx context.Context, logger logr.Logger, cl client.Client, recorder record.EventRecorder, original, upgraded v1alpha1.Agent) error {
+	desired := dryRunReport(original, upgraded)
+	if err := controllerutil.SetControllerReference(&original, &desired, cl.Scheme()); err != nil {
+		return fmt.Errorf("failed to set controller reference: %w", err)
+	}
+
+	existing := &corev1.ConfigMap{}
+	nns := types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}
+	err := cl.Get(ctx, nns, existing)
+	if err != nil && k8serrors.IsNotFound(err) {
+		if err = cl.Create(ctx, &desired); err != nil {
+			return fmt.Errorf("failed to create the upgrade report: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to get the upgrade report: %w", err)
+	} else {
+		if reflect.DeepEqual(existing.Data, desired.Data) {
+			// this report has been published already, no need to record the event once again
+			return nil
+		}
+
+		updated := existing.DeepCopy()
+		updated.Labels = desired.Labels
+		updated.Annotations = desired.Annotations
+		updated.OwnerReferences = desired.OwnerReferences
+		updated.Data = desired.Data
+		if err := cl.Patch(ctx, updated, client.MergeFrom(existing)); err != nil {
+			return fmt.Errorf("failed to update the upgrade report: %w", err)
+		}
+	}
+
+	recorder.Event(&original, "Normal", "UpgradeDryRun", fmt.Sprintf("upgrade from version %s to %s not applied, the pending changes are in the config map %s",
+		original.Status.Version, upgraded.Status.Version, desired.Name))
+	logger.Info("instance upgrade reported", "name", original.Name, "namespace", original.Namespace, "report", desired.Name)
+	return nil
+}
+
+// dryRunReport builds the config map with the messages and a diff of each component config for the given upgrade,
+// along with a diff of each config overlay the upgrade changed.
+func dryRunReport(original, upgraded v1alpha1.Agent) corev1.ConfigMap {
+	// the report isn't labeled as managed by the operator, otherwise the config map reconciliation would prune it
+	labels := map[string]string{
+		"app.kubernetes.io/instance":  fmt.Sprintf("%s.%s", original.Namespace, original.Name),
+		"app.kubernetes.io/component": "upgrade-report",
+	}
+
+	data := map[string]string{
+		"messages": strings.Join(upgraded.Status.Messages[len(original.Status.Messages):], "\n"),
+	}
+
+	originalComponents := components(&original)
+	for i, component := range components(&upgraded) {
+		data[fmt.Sprintf("%s.diff", component.name)] = configDiff(component.name, originalComponents[i].spec.Config, component.spec.Config)
+		if overlay := originalComponents[i].spec.ConfigOverlay; overlay != component.spec.ConfigOverlay {
+			name := fmt.Sprintf("%s overlay", component.name)
+			data[fmt.Sprintf("%s-overlay.diff", component.name)] = configDiff(name, overlay, component.spec.ConfigOverlay)
+		}
+	}
+
+	return corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      naming.UpgradeReport(original),
+			Namespace: original.Namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				"otel.splunk.com/upgrade-from": original.Status.Version,
+				"otel.splunk.com/upgrade-to":   upgraded.Status.Version,
+			},
+		},
+		Data: data,
+	}
+}
+
+// configDiff returns a unified diff between both configs. Both sides are normalized first, so that reformatting
+// done by the upgrade steps doesn't show up as a change.
+func configDiff(name, from, to string) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(strings.TrimSuffix(normalizeConfig(from), "\n")),
+		B:        difflib.SplitLines(strings.TrimSuffix(normalizeConfig(to), "\n")),
+		FromFile: fmt.Sprintf("%s (current)", name),
+		ToFile:   fmt.Sprintf("%s (upgraded)", name),
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Sprintf("failed to compute the diff: %v", err)
+	}
+	return diff
+}
+
+func normalizeConfig(config string) string {
+	cfg, err := adapters.ConfigFromString(config)
+	if err != nil || len(cfg) == 0 {
+		return config
+	}
+
+	res, err := yaml.Marshal(cfg)
+	if err != nil {
+		return config
+	}
+	return string(res)
+}
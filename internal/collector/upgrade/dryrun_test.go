@@ -0,0 +1,105 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/upgrade"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/version"
+)
+
+func TestDryRunPublishesReport(t *testing.T) {
+	// prepare
+	nsn := types.NamespacedName{Name: "my-dry-run-instance", Namespace: "default"}
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nsn.Name,
+			Namespace: nsn.Namespace,
+			Annotations: map[string]string{
+				upgrade.AnnotationDryRun: "true",
+			},
+		},
+		Spec: v1alpha1.AgentSpec{
+			Gateway: v1alpha1.CollectorSpec{
+				Config: `
+receivers:
+  influxdb:
+    endpoint: 0.0.0.0:8080
+    metrics_schema: telegraf-prometheus-v1
+`,
+			},
+		},
+	}
+	err := k8sClient.Create(context.Background(), &existing)
+	require.NoError(t, err)
+
+	existing.Status.Version = "0.30.0"
+	err = k8sClient.Status().Update(context.Background(), &existing)
+	require.NoError(t, err)
+
+	currentV := version.Get()
+	currentV.Collector = "0.71.0"
+
+	// test
+	res, err := upgrade.Instance(context.Background(), logger, currentV, k8sClient, record.NewFakeRecorder(10), existing)
+	require.NoError(t, err)
+
+	// verify
+	assert.Equal(t, existing, res)
+
+	persisted := &v1alpha1.Agent{}
+	require.NoError(t, k8sClient.Get(context.Background(), nsn, persisted))
+	assert.Equal(t, "0.30.0", persisted.Status.Version)
+	assert.Equal(t, existing.Spec.Gateway.Config, persisted.Spec.Gateway.Config)
+
+	report := &corev1.ConfigMap{}
+	require.NoError(t, k8sClient.Get(context.Background(), types.NamespacedName{Name: "my-dry-run-instance-upgrade-report", Namespace: "default"}, report))
+	assert.Equal(t, "0.30.0", report.Annotations["otel.splunk.com/upgrade-from"])
+	assert.Equal(t, "0.71.0", report.Annotations["otel.splunk.com/upgrade-to"])
+	assert.Contains(t, report.Data["messages"], "gateway: upgrade to v0.31.0 dropped the 'metrics_schema' field from \"influxdb\" receiver")
+	assert.Contains(t, report.Data["gateway.diff"], "-    metrics_schema: telegraf-prometheus-v1")
+	assert.Empty(t, report.Data["agent.diff"])
+
+	// cleanup
+	assert.NoError(t, k8sClient.Delete(context.Background(), &existing))
+}
+
+func TestDryRun(t *testing.T) {
+	for _, tt := range []struct {
+		desc        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{"no-annotations", nil, false},
+		{"enabled", map[string]string{upgrade.AnnotationDryRun: "true"}, true},
+		{"enabled-mixed-case", map[string]string{upgrade.AnnotationDryRun: "True"}, true},
+		{"disabled", map[string]string{upgrade.AnnotationDryRun: "false"}, false},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			otelcol := v1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			assert.Equal(t, tt.expected, upgrade.DryRun(otelcol))
+		})
+	}
+}
@@ -22,6 +22,6 @@ import (
 )
 
 // nolint unused
-func noop(cl client.Client, otelcol *v1alpha1.Agent) (*v1alpha1.Agent, error) {
-	return otelcol, nil
+func noop(cl client.Client, spec *v1alpha1.CollectorSpec) ([]string, error) {
+	return nil, nil
 }
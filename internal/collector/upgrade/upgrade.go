@@ -23,6 +23,7 @@ import (
 
 	semver "github.com/Masterminds/semver/v3"
 	"github.com/go-logr/logr"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
@@ -30,7 +31,7 @@ import (
 )
 
 // ManagedInstances finds all the otelcol instances for the current operator and upgrades them, if necessary.
-func ManagedInstances(ctx context.Context, logger logr.Logger, ver version.Version, cl client.Client) error {
+func ManagedInstances(ctx context.Context, logger logr.Logger, ver version.Version, cl client.Client, recorder record.EventRecorder) error {
 	logger.Info("looking for managed instances to upgrade")
 
 	opts := []client.ListOption{
@@ -44,42 +45,114 @@ func ManagedInstances(ctx context.Context, logger logr.Logger, ver version.Versi
 	}
 
 	for i := range list.Items {
-		original := list.Items[i]
-		upgraded, err := ManagedInstance(ctx, logger, ver, cl, original)
-		if err != nil {
+		// paused instances are upgraded once resumed, by their reconciliation
+		if list.Items[i].Paused() {
+			logger.Info("skipping the upgrade of a paused instance", "name", list.Items[i].Name, "namespace", list.Items[i].Namespace)
+			continue
+		}
+		if _, err := Instance(ctx, logger, ver, cl, recorder, list.Items[i]); err != nil {
 			// nothing to do at this level, just go to the next instance
 			continue
 		}
+	}
 
-		if !reflect.DeepEqual(upgraded, list.Items[i]) {
-			// the resource update overrides the status, so, keep it so that we can reset it later
-			st := upgraded.Status
-			patch := client.MergeFrom(&original)
-			if err := cl.Patch(ctx, &upgraded, patch); err != nil {
-				logger.Error(err, "failed to apply changes to instance", "name", upgraded.Name, "namespace", upgraded.Namespace)
-				continue
-			}
+	if len(list.Items) == 0 {
+		logger.Info("no instances to upgrade")
+	}
 
-			// the status object requires its own update
-			upgraded.Status = st
-			if err := cl.Status().Patch(ctx, &upgraded, patch); err != nil {
-				logger.Error(err, "failed to apply changes to instance's status object", "name", upgraded.Name, "namespace", upgraded.Namespace)
-				continue
-			}
+	return nil
+}
 
-			logger.Info("instance upgraded", "name", upgraded.Name, "namespace", upgraded.Namespace, "version", upgraded.Status.Version)
+// Outdated reports whether the given otelcol instance was last reconciled by an older collector version than the current
+// one, or runs a default config that differs from the default config of the current operator.
+func Outdated(otelcol v1alpha1.Agent, currentV version.Version) bool {
+	for _, name := range v1alpha1.Components {
+		if otelcol.DefaultConfigOutdated(name) {
+			return true
 		}
 	}
 
-	if len(list.Items) == 0 {
-		logger.Info("no instances to upgrade")
+	// this is likely a new instance, reconcile.Self will take care of setting its version
+	if otelcol.Status.Version == "" {
+		return false
 	}
 
-	return nil
+	instanceV, err := semver.NewVersion(otelcol.Status.Version)
+	if err != nil {
+		// let the upgrade routine report the unparseable version
+		return true
+	}
+
+	latestV, err := semver.NewVersion(currentV.Collector)
+	if err != nil {
+		return false
+	}
+
+	return instanceV.LessThan(latestV)
+}
+
+// Instance upgrades the given otelcol instance and, when the upgrade changed anything, persists its spec and status.
+// An event is recorded for each message added by the upgrade steps, so that the changes are visible on the instance.
+// Instances annotated with AnnotationDryRun are not changed: a report of the pending changes is published instead.
+func Instance(ctx context.Context, logger logr.Logger, ver version.Version, cl client.Client, recorder record.EventRecorder, original v1alpha1.Agent) (v1alpha1.Agent, error) {
+	upgraded, err := ManagedInstance(ctx, logger, ver, cl, original)
+	if err != nil {
+		return original, err
+	}
+
+	if reflect.DeepEqual(upgraded, original) {
+		return original, nil
+	}
+
+	if DryRun(original) {
+		if err := publishDryRunReport(ctx, logger, cl, recorder, original, upgraded); err != nil {
+			logger.Error(err, "failed to publish the upgrade report", "name", original.Name, "namespace", original.Namespace)
+			return original, err
+		}
+		return original, nil
+	}
+
+	// the events record every message, the status only keeps the most recent ones
+	messages := upgraded.Status.Messages[len(original.Status.Messages):]
+	upgraded.Status.Messages = append([]string(nil), original.Status.Messages...)
+	upgraded.Status.AddMessages(messages...)
+
+	// the resource update overrides the status, so, keep it so that we can reset it later
+	st := upgraded.Status
+	patch := client.MergeFrom(&original)
+	if err := cl.Patch(ctx, &upgraded, patch); err != nil {
+		logger.Error(err, "failed to apply changes to instance", "name", upgraded.Name, "namespace", upgraded.Namespace)
+		return original, err
+	}
+
+	// the status object requires its own update
+	upgraded.Status = st
+	if err := cl.Status().Patch(ctx, &upgraded, patch); err != nil {
+		logger.Error(err, "failed to apply changes to instance's status object", "name", upgraded.Name, "namespace", upgraded.Namespace)
+		return original, err
+	}
+
+	for _, msg := range messages {
+		recorder.Event(&upgraded, "Normal", "Upgrade", msg)
+	}
+	if upgraded.Status.Version != original.Status.Version {
+		recorder.Event(&upgraded, "Normal", "Upgraded", fmt.Sprintf("upgraded from version %s to %s", original.Status.Version, upgraded.Status.Version))
+	}
+
+	logger.Info("instance upgraded", "name", upgraded.Name, "namespace", upgraded.Namespace, "version", upgraded.Status.Version)
+	return upgraded, nil
 }
 
 // ManagedInstance performs the necessary changes to bring the given otelcol instance to the current version.
 func ManagedInstance(ctx context.Context, logger logr.Logger, currentV version.Version, cl client.Client, otelcol v1alpha1.Agent) (v1alpha1.Agent, error) {
+	for _, name := range v1alpha1.Components {
+		// default configs are written for the collector version shipped with the operator, so they're replaced
+		// with the current default rather than migrated, whatever the version the instance was reconciled with
+		if otelcol.RefreshDefaultConfig(name) {
+			otelcol.Status.Messages = append(otelcol.Status.Messages, fmt.Sprintf("%s: refreshed the operator default configuration", name))
+		}
+	}
+
 	// this is likely a new instance, assume it's already up to date
 	if otelcol.Status.Version == "" {
 		return otelcol, nil
@@ -91,23 +164,43 @@ func ManagedInstance(ctx context.Context, logger logr.Logger, currentV version.V
 		return otelcol, err
 	}
 
+	targetV, err := semver.NewVersion(currentV.Collector)
+	if err != nil {
+		logger.Error(err, "failed to parse the current OpenTelemetry Collector version", "version", currentV.Collector)
+		return otelcol, err
+	}
+
 	if instanceV.GreaterThan(&Latest.Version) {
 		logger.Info("skipping upgrade for OpenTelemetry Collector instance, as it's newer than our latest version", "name", otelcol.Name, "namespace", otelcol.Namespace, "version", otelcol.Status.Version, "latest", Latest.Version.String())
 		return otelcol, nil
 	}
 
 	for _, available := range versions {
-		if available.GreaterThan(instanceV) {
-			upgraded, err := available.upgrade(cl, &otelcol)
-
-			if err != nil {
-				logger.Error(err, "failed to upgrade managed otelcol instances", "name", otelcol.Name, "namespace", otelcol.Namespace)
-				return otelcol, err
+		// steps for collector versions newer than the one we deploy would produce configs it can't load
+		if available.GreaterThan(instanceV) && !available.GreaterThan(targetV) {
+			for _, component := range components(&otelcol) {
+				spec := component.spec
+				if otelcol.UsesDefaultConfig(component.name) {
+					// the default config was refreshed above, only the overlay written by the user is migrated
+					spec = &v1alpha1.CollectorSpec{ConfigOverlay: component.spec.ConfigOverlay}
+				}
+
+				messages, err := available.upgrade(cl, spec)
+				if err != nil {
+					logger.Error(err, "failed to upgrade managed otelcol instances", "name", otelcol.Name, "namespace", otelcol.Namespace, "component", component.name)
+					return otelcol, err
+				}
+
+				component.spec.ConfigOverlay = spec.ConfigOverlay
+
+				for _, msg := range messages {
+					otelcol.Status.Messages = append(otelcol.Status.Messages, fmt.Sprintf("%s: %s", component.name, msg))
+				}
 			}
 
 			logger.V(1).Info("step upgrade", "name", otelcol.Name, "namespace", otelcol.Namespace, "version", available.String())
-			upgraded.Status.Version = available.String()
-			otelcol = *upgraded
+			otelcol.Status.Messages = append(otelcol.Status.Messages, fmt.Sprintf("applied the upgrade step for v%s", available.String()))
+			otelcol.Status.Version = available.String()
 		}
 	}
 
@@ -117,3 +210,17 @@ func ManagedInstance(ctx context.Context, logger logr.Logger, currentV version.V
 	logger.V(1).Info("final version", "name", otelcol.Name, "namespace", otelcol.Namespace, "version", otelcol.Status.Version)
 	return otelcol, nil
 }
+
+type component struct {
+	name string
+	spec *v1alpha1.CollectorSpec
+}
+
+// components returns the collector specs of the given instance, named after the config map they end up in.
+func components(otelcol *v1alpha1.Agent) []component {
+	var res []component
+	for _, name := range v1alpha1.Components {
+		res = append(res, component{name: name, spec: otelcol.CollectorSpec(name)})
+	}
+	return res
+}
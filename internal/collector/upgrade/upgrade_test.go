@@ -17,12 +17,16 @@ package upgrade_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
@@ -62,7 +66,7 @@ func TestShouldUpgradeAllToLatest(t *testing.T) {
 	require.Equal(t, "0.0.1", persisted.Status.Version)
 
 	// test
-	err = upgrade.ManagedInstances(context.Background(), logger, currentV, k8sClient)
+	err = upgrade.ManagedInstances(context.Background(), logger, currentV, k8sClient, record.NewFakeRecorder(10))
 	assert.NoError(t, err)
 
 	// verify
@@ -99,6 +103,120 @@ func TestUpgradeUpToLatestKnownVersion(t *testing.T) {
 	assert.Equal(t, "0.10.0", res.Status.Version)
 }
 
+func TestUpgradeAppliesEveryStep(t *testing.T) {
+	// prepare
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AgentSpec{
+			Agent: v1alpha1.CollectorSpec{
+				Config: `
+extensions:
+  memory_ballast:
+    size_mib: 165
+receivers:
+  receiver_creator:
+    receivers:
+      redis:
+        rule: type.port && pod.name matches "redis"
+processors:
+  resourcedetection:
+    detectors: [system]
+    attributes: [host.name]
+exporters:
+  logging:
+    loglevel: debug
+  sapm:
+    endpoint: https://ingest.us0.signalfx.com/v2/trace
+service:
+  extensions: [memory_ballast]
+  pipelines:
+    traces:
+      receivers: [receiver_creator]
+      processors: [resourcedetection]
+      exporters: [sapm, logging]
+`,
+			},
+		}}
+	existing.Status.Version = "0.71.0"
+
+	for _, tt := range []struct {
+		desc      string
+		collector string
+		expected  []string
+	}{
+		{
+			"up to the latest step",
+			upgrade.Latest.String(),
+			[]string{"v0.72.0", "v0.81.0", "v0.86.0", "v0.97.0", "v0.116.0"},
+		},
+		{
+			"up to the shipped collector version",
+			version.Collector(),
+			nil,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			currentV := version.Get()
+			currentV.Collector = tt.collector
+
+			// test
+			res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+			require.NoError(t, err)
+
+			// verify
+			var applied []string
+			for _, msg := range res.Status.Messages {
+				if step := strings.TrimPrefix(msg, "applied the upgrade step for "); step != msg {
+					applied = append(applied, step)
+				}
+			}
+			assert.Equal(t, tt.expected, applied)
+			assert.Equal(t, tt.collector, res.Status.Version)
+		})
+	}
+
+	t.Run("rewrites every deprecated component", func(t *testing.T) {
+		currentV := version.Get()
+		currentV.Collector = upgrade.Latest.String()
+
+		// test
+		res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+		require.NoError(t, err)
+
+		// verify
+		assert.Equal(t, `exporters:
+  debug:
+    verbosity: detailed
+  otlphttp:
+    traces_endpoint: https://ingest.us0.signalfx.com/v2/trace/otlp
+extensions: {}
+processors:
+  resourcedetection:
+    detectors:
+    - system
+receivers:
+  receiver_creator:
+    receivers:
+      redis:
+        rule: type == "port" && pod.name matches "redis"
+service:
+  extensions: []
+  pipelines:
+    traces:
+      exporters:
+      - otlphttp
+      - debug
+      processors:
+      - resourcedetection
+      receivers:
+      - receiver_creator
+`, res.Spec.Agent.Config)
+	})
+}
+
 func TestVersionsShouldNotBeChanged(t *testing.T) {
 	for _, tt := range []struct {
 		desc            string
@@ -140,3 +258,100 @@ func TestVersionsShouldNotBeChanged(t *testing.T) {
 		})
 	}
 }
+
+func TestOutdated(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		v        string
+		current  string
+		expected bool
+	}{
+		{"new-instance", "", "0.71.0", false},
+		{"same-version", "0.71.0", "0.71.0", false},
+		{"older-version", "0.30.0", "0.71.0", true},
+		{"newer-version", "100.0.0", "0.71.0", false},
+		{"unparseable", "unparseable", "0.71.0", true},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			// prepare
+			existing := v1alpha1.Agent{}
+			existing.Status.Version = tt.v
+
+			currentV := version.Get()
+			currentV.Collector = tt.current
+
+			// test and verify
+			assert.Equal(t, tt.expected, upgrade.Outdated(existing, currentV))
+		})
+	}
+}
+
+func TestOutdatedDefaultConfig(t *testing.T) {
+	// prepare
+	olderDefault := "receivers:\n  otlp:\n"
+	existing := v1alpha1.Agent{}
+	existing.Default()
+	existing.Status.Version = "0.71.0"
+
+	currentV := version.Get()
+	currentV.Collector = "0.71.0"
+	assert.False(t, upgrade.Outdated(existing, currentV), "the current default configs shouldn't be outdated")
+
+	existing.Annotations[v1alpha1.DefaultConfigAnnotation("gateway")] = fmt.Sprintf("%x", sha256.Sum256([]byte(olderDefault)))
+	existing.Spec.Gateway.Config = olderDefault
+
+	// test
+	outdated := upgrade.Outdated(existing, currentV)
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+
+	// verify
+	assert.True(t, outdated, "the default config of another operator should be outdated")
+	assert.NoError(t, err)
+	assert.False(t, upgrade.Outdated(res, currentV))
+	assert.True(t, res.UsesDefaultConfig("gateway"))
+	assert.Equal(t, "0.71.0", res.Status.Version)
+	assert.Equal(t, []string{"gateway: refreshed the operator default configuration"}, res.Status.Messages)
+}
+
+func TestDefaultConfigRefreshed(t *testing.T) {
+	// prepare
+	olderDefault := `receivers:
+  influxdb:
+    metrics_schema: telegraf-prometheus-v1
+`
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "default",
+			Annotations: map[string]string{
+				v1alpha1.DefaultConfigAnnotation("agent"): fmt.Sprintf("%x", sha256.Sum256([]byte(olderDefault))),
+			},
+		},
+		Spec: v1alpha1.AgentSpec{
+			Agent:           v1alpha1.CollectorSpec{Config: olderDefault, ConfigOverlay: olderDefault},
+			ClusterReceiver: v1alpha1.CollectorSpec{Config: olderDefault},
+		}}
+	existing.Status.Version = "0.30.0"
+
+	currentV := version.Get()
+	currentV.Collector = "0.31.0"
+
+	defaulted := v1alpha1.Agent{}
+	defaulted.Default()
+
+	// test
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+	assert.NoError(t, err)
+
+	// verify
+	assert.Equal(t, defaulted.Spec.Agent.Config, res.Spec.Agent.Config)
+	assert.True(t, res.UsesDefaultConfig("agent"))
+	assert.Equal(t, "receivers:\n  influxdb: {}\n", res.Spec.Agent.ConfigOverlay, "overlays of default configs should be migrated")
+	assert.Equal(t, "receivers:\n  influxdb: {}\n", res.Spec.ClusterReceiver.Config, "user provided configs should be migrated")
+	assert.Equal(t, []string{
+		"agent: refreshed the operator default configuration",
+		"agent: upgrade to v0.31.0 dropped the 'metrics_schema' field from \"influxdb\" receiver in the config overlay",
+		"cluster-receiver: upgrade to v0.31.0 dropped the 'metrics_schema' field from \"influxdb\" receiver",
+		"applied the upgrade step for v0.31.0",
+	}, res.Status.Messages)
+}
@@ -0,0 +1,77 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+)
+
+// sapmSettingsKeptByOTLPHTTP are the sapm exporter settings with the same meaning in the otlphttp exporter.
+var sapmSettingsKeptByOTLPHTTP = map[string]bool{
+	"timeout":          true,
+	"sending_queue":    true,
+	"retry_on_failure": true,
+	"tls":              true,
+	"compression":      true,
+}
+
+func upgrade0_116_0(cl client.Client, spec *v1alpha1.CollectorSpec) ([]string, error) {
+	return migrateConfig(spec, "0.116.0", func(cfg map[interface{}]interface{}) []string {
+		var messages []string
+		for _, name := range componentsOfType(cfg, "exporters", "sapm") {
+			// the sapm exporter is deprecated, Splunk Observability Cloud ingests traces over OTLP/HTTP
+			replacement := "otlphttp" + strings.TrimPrefix(name, "sapm")
+			if err := renameComponent(cfg, "exporters", name, replacement); err != nil {
+				messages = append(messages, fmt.Sprintf("upgrade to v0.116.0 couldn't replace the %q exporter with %q: %v", name, replacement, err))
+				continue
+			}
+
+			settings := componentConfig(cfg, "exporters", replacement)
+			migrated := map[interface{}]interface{}{}
+			var dropped []string
+			for k, v := range settings {
+				key := fmt.Sprintf("%v", k)
+				switch {
+				case key == "access_token":
+					migrated["headers"] = map[interface{}]interface{}{"X-SF-Token": v}
+				case key == "endpoint":
+					endpoint := fmt.Sprintf("%v", v)
+					if strings.HasSuffix(endpoint, "/v2/trace") {
+						endpoint += "/otlp"
+					}
+					migrated["traces_endpoint"] = endpoint
+				case sapmSettingsKeptByOTLPHTTP[key]:
+					migrated[k] = v
+				default:
+					dropped = append(dropped, key)
+				}
+			}
+			cfg["exporters"].(map[interface{}]interface{})[replacement] = migrated
+
+			messages = append(messages, fmt.Sprintf("upgrade to v0.116.0 replaced the %q exporter with %q", name, replacement))
+			if len(dropped) > 0 {
+				sort.Strings(dropped)
+				messages = append(messages, fmt.Sprintf("upgrade to v0.116.0 dropped the '%s' field(s) from %q exporter, as they aren't supported by %q", strings.Join(dropped, "', '"), name, replacement))
+			}
+		}
+		return messages
+	})
+}
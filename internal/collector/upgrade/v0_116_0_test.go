@@ -0,0 +1,167 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/upgrade"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/version"
+)
+
+func TestSapmExporterReplacedWithOTLPHTTP(t *testing.T) {
+	// prepare
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AgentSpec{
+			Agent: v1alpha1.CollectorSpec{
+				Config: `
+receivers:
+  otlp:
+exporters:
+  sapm:
+    access_token: ${SPLUNK_ACCESS_TOKEN}
+    endpoint: https://ingest.${SPLUNK_REALM}.signalfx.com/v2/trace
+    max_connections: 100
+    num_workers: 8
+    sending_queue:
+      num_consumers: 32
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [sapm]
+`,
+			},
+		}}
+	existing.Status.Version = "0.115.0"
+
+	currentV := version.Get()
+	currentV.Collector = "0.116.0"
+
+	// test
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+	assert.NoError(t, err)
+
+	// verify
+	assert.Equal(t, `exporters:
+  otlphttp:
+    headers:
+      X-SF-Token: ${SPLUNK_ACCESS_TOKEN}
+    sending_queue:
+      num_consumers: 32
+    traces_endpoint: https://ingest.${SPLUNK_REALM}.signalfx.com/v2/trace/otlp
+receivers:
+  otlp: null
+service:
+  pipelines:
+    traces:
+      exporters:
+      - otlphttp
+      receivers:
+      - otlp
+`, res.Spec.Agent.Config)
+	assert.Equal(t, []string{
+		"agent: upgrade to v0.116.0 replaced the \"sapm\" exporter with \"otlphttp\"",
+		"agent: upgrade to v0.116.0 dropped the 'max_connections', 'num_workers' field(s) from \"sapm\" exporter, as they aren't supported by \"otlphttp\"",
+		"applied the upgrade step for v0.116.0",
+	}, res.Status.Messages)
+}
+
+func TestSapmExporterNotReplacedOnConflict(t *testing.T) {
+	// prepare
+	config := `exporters:
+  otlphttp:
+    endpoint: http://localhost:4318
+  sapm:
+    endpoint: http://localhost:7276/v2/trace
+`
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AgentSpec{
+			Agent: v1alpha1.CollectorSpec{Config: config},
+		}}
+	existing.Status.Version = "0.115.0"
+
+	currentV := version.Get()
+	currentV.Collector = "0.116.0"
+
+	// test
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+	assert.NoError(t, err)
+
+	// verify
+	assert.Equal(t, config, res.Spec.Agent.Config)
+	assert.Equal(t, []string{
+		"agent: upgrade to v0.116.0 couldn't replace the \"sapm\" exporter with \"otlphttp\": the exporters \"otlphttp\" is already defined",
+		"applied the upgrade step for v0.116.0",
+	}, res.Status.Messages)
+}
+
+func TestSapmExporterReplacedInOverlay(t *testing.T) {
+	// prepare
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AgentSpec{
+			Gateway: v1alpha1.CollectorSpec{
+				ConfigOverlay: `
+exporters:
+  sapm:
+    endpoint: https://ingest.us0.signalfx.com/v2/trace
+service:
+  pipelines:
+    traces:
+      exporters: [sapm]
+`,
+			},
+		}}
+	existing.Status.Version = "0.115.0"
+
+	currentV := version.Get()
+	currentV.Collector = "0.116.0"
+
+	// test
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+	assert.NoError(t, err)
+
+	// verify
+	assert.Equal(t, `exporters:
+  otlphttp:
+    traces_endpoint: https://ingest.us0.signalfx.com/v2/trace/otlp
+service:
+  pipelines:
+    traces:
+      exporters:
+      - otlphttp
+`, res.Spec.Gateway.ConfigOverlay)
+	assert.Equal(t, []string{
+		"gateway: upgrade to v0.116.0 replaced the \"sapm\" exporter with \"otlphttp\" in the config overlay",
+		"applied the upgrade step for v0.116.0",
+	}, res.Status.Messages)
+}
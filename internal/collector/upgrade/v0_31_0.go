@@ -19,56 +19,40 @@ import (
 	"fmt"
 	"strings"
 
-	"gopkg.in/yaml.v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
-	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/adapters"
 )
 
-func upgrade0_31_0(cl client.Client, otelcol *v1alpha1.Agent) (*v1alpha1.Agent, error) {
-	if len(otelcol.Spec.Agent.Config) == 0 {
-		return otelcol, nil
-	}
-
-	cfg, err := adapters.ConfigFromString(otelcol.Spec.Agent.Config)
-	if err != nil {
-		return otelcol, fmt.Errorf("couldn't upgrade to v0.31.0, failed to parse configuration: %w", err)
-	}
-
-	receivers, ok := cfg["receivers"].(map[interface{}]interface{})
-	if !ok {
-		// no receivers? no need to fail because of that
-		return otelcol, nil
-	}
+func upgrade0_31_0(cl client.Client, spec *v1alpha1.CollectorSpec) ([]string, error) {
+	return migrateConfig(spec, "0.31.0", func(cfg map[interface{}]interface{}) []string {
+		receivers, ok := cfg["receivers"].(map[interface{}]interface{})
+		if !ok {
+			// no receivers? no need to fail because of that
+			return nil
+		}
 
-	for k, v := range receivers {
-		// from the changelog https://github.com/signalfx/splunk-otel-collector/blob/main/CHANGELOG.md#v0310-beta
-		// Here is the upstream PR https://github.com/signalfx/splunk-otel-collector-contrib/pull/4277
+		var messages []string
+		for k, v := range receivers {
+			// from the changelog https://github.com/signalfx/splunk-otel-collector/blob/main/CHANGELOG.md#v0310-beta
+			// Here is the upstream PR https://github.com/signalfx/splunk-otel-collector-contrib/pull/4277
 
-		// Remove deprecated field metrics_schema from influxdb receiver
-		if strings.HasPrefix(k.(string), "influxdb") {
-			influxdbConfig, ok := v.(map[interface{}]interface{})
-			if !ok {
-				// no influxdbConfig? no need to fail because of that
-				return otelcol, nil
-			}
-			for fieldKey := range influxdbConfig {
-				if strings.HasPrefix(fieldKey.(string), "metrics_schema") {
-					delete(influxdbConfig, fieldKey)
-					otelcol.Status.Messages = append(otelcol.Status.Messages, fmt.Sprintf("upgrade to v0.31.0 dropped the 'metrics_schema' field from %q receiver", k))
+			// Remove deprecated field metrics_schema from influxdb receiver
+			if strings.HasPrefix(k.(string), "influxdb") {
+				influxdbConfig, ok := v.(map[interface{}]interface{})
+				if !ok {
+					// no influxdbConfig? no need to fail because of that
 					continue
 				}
+				for fieldKey := range influxdbConfig {
+					if strings.HasPrefix(fieldKey.(string), "metrics_schema") {
+						delete(influxdbConfig, fieldKey)
+						messages = append(messages, fmt.Sprintf("upgrade to v0.31.0 dropped the 'metrics_schema' field from %q receiver", k))
+						continue
+					}
+				}
 			}
 		}
-	}
-
-	cfg["receivers"] = receivers
-	res, err := yaml.Marshal(cfg)
-	if err != nil {
-		return otelcol, fmt.Errorf("couldn't upgrade to v0.31.0, failed to marshall back configuration: %w", err)
-	}
-
-	otelcol.Spec.Agent.Config = string(res)
-	return otelcol, nil
+		return messages
+	})
 }
@@ -61,8 +61,11 @@ service:
 		}}
 	existing.Status.Version = "0.30.0"
 
+	currentV := version.Get()
+	currentV.Collector = "0.31.0"
+
 	// test
-	res, err := upgrade.ManagedInstance(context.Background(), logger, version.Get(), nil, existing)
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
 	assert.NoError(t, err)
 
 	// verify
@@ -80,5 +83,44 @@ service:
       receivers:
       - influxdb
 `, res.Spec.Agent.Config)
-	assert.Equal(t, "upgrade to v0.31.0 dropped the 'metrics_schema' field from \"influxdb\" receiver", res.Status.Messages[0])
+	assert.Equal(t, "agent: upgrade to v0.31.0 dropped the 'metrics_schema' field from \"influxdb\" receiver", res.Status.Messages[0])
+}
+
+func TestInfluxdbReceiverPropertyDropAllComponents(t *testing.T) {
+	// prepare
+	config := `receivers:
+  influxdb:
+    endpoint: 0.0.0.0:8080
+    metrics_schema: telegraf-prometheus-v1
+`
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AgentSpec{
+			Agent:           v1alpha1.CollectorSpec{Config: config},
+			ClusterReceiver: v1alpha1.CollectorSpec{Config: config},
+			Gateway:         v1alpha1.CollectorSpec{Config: config},
+		}}
+	existing.Status.Version = "0.30.0"
+
+	currentV := version.Get()
+	currentV.Collector = "0.31.0"
+
+	// test
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+	assert.NoError(t, err)
+
+	// verify
+	expected := `receivers:
+  influxdb:
+    endpoint: 0.0.0.0:8080
+`
+	assert.Equal(t, expected, res.Spec.Agent.Config)
+	assert.Equal(t, expected, res.Spec.ClusterReceiver.Config)
+	assert.Equal(t, expected, res.Spec.Gateway.Config)
+	assert.Contains(t, res.Status.Messages, "agent: upgrade to v0.31.0 dropped the 'metrics_schema' field from \"influxdb\" receiver")
+	assert.Contains(t, res.Status.Messages, "cluster-receiver: upgrade to v0.31.0 dropped the 'metrics_schema' field from \"influxdb\" receiver")
+	assert.Contains(t, res.Status.Messages, "gateway: upgrade to v0.31.0 dropped the 'metrics_schema' field from \"influxdb\" receiver")
 }
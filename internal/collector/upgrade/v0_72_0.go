@@ -0,0 +1,100 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+)
+
+// loglevelToVerbosity maps the deprecated 'loglevel' of the logging exporter to the closest 'verbosity'.
+var loglevelToVerbosity = map[string]string{
+	"debug": "detailed",
+	"info":  "normal",
+	"warn":  "basic",
+	"error": "basic",
+}
+
+// legacyObserverRule matches the endpoint type checks of the k8s_observer in the legacy rule syntax of the
+// receiver_creator, like 'type.pod', which was replaced by expressions like 'type == "pod"'.
+var legacyObserverRule = regexp.MustCompile(`\btype\.(pod|port|hostport|container)\b`)
+
+func upgrade0_72_0(cl client.Client, spec *v1alpha1.CollectorSpec) ([]string, error) {
+	return migrateConfig(spec, "0.72.0", func(cfg map[interface{}]interface{}) []string {
+		var messages []string
+		for _, name := range componentsOfType(cfg, "exporters", "logging") {
+			exporter := componentConfig(cfg, "exporters", name)
+			loglevel, ok := exporter["loglevel"]
+			if !ok {
+				continue
+			}
+
+			// the 'loglevel' setting is deprecated in favor of 'verbosity'
+			delete(exporter, "loglevel")
+			if _, exists := exporter["verbosity"]; exists {
+				messages = append(messages, fmt.Sprintf("upgrade to v0.72.0 dropped the 'loglevel' field from %q exporter, as 'verbosity' is already set", name))
+				continue
+			}
+
+			level := fmt.Sprintf("%v", loglevel)
+			verbosity, known := loglevelToVerbosity[level]
+			if !known {
+				messages = append(messages, fmt.Sprintf("upgrade to v0.72.0 dropped the unknown 'loglevel' %q from %q exporter", level, name))
+				continue
+			}
+
+			exporter["verbosity"] = verbosity
+			messages = append(messages, fmt.Sprintf("upgrade to v0.72.0 replaced the 'loglevel: %v' field from %q exporter with 'verbosity: %s'", level, name, verbosity))
+		}
+		return append(messages, migrateObserverRules(cfg)...)
+	})
+}
+
+// migrateObserverRules rewrites the rules of the receivers created for the k8s_observer endpoints from the legacy
+// syntax into expressions.
+func migrateObserverRules(cfg map[interface{}]interface{}) []string {
+	var messages []string
+	for _, name := range componentsOfType(cfg, "receivers", "receiver_creator") {
+		created, ok := componentConfig(cfg, "receivers", name)["receivers"].(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+
+		var receivers []string
+		for k := range created {
+			receivers = append(receivers, fmt.Sprintf("%v", k))
+		}
+		sort.Strings(receivers)
+
+		for _, receiver := range receivers {
+			template, ok := created[receiver].(map[interface{}]interface{})
+			if !ok {
+				continue
+			}
+			rule, ok := template["rule"].(string)
+			if !ok || !legacyObserverRule.MatchString(rule) {
+				continue
+			}
+			template["rule"] = legacyObserverRule.ReplaceAllString(rule, `type == "$1"`)
+			messages = append(messages, fmt.Sprintf("upgrade to v0.72.0 rewrote the legacy rule of %q in %q receiver: %s", receiver, name, template["rule"]))
+		}
+	}
+	return messages
+}
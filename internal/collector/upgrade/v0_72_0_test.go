@@ -0,0 +1,156 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/upgrade"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/version"
+)
+
+func TestLoggingExporterLoglevelToVerbosity(t *testing.T) {
+	// prepare
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AgentSpec{
+			Agent: v1alpha1.CollectorSpec{
+				Config: `
+exporters:
+  logging:
+    loglevel: debug
+  logging/verbose:
+    loglevel: info
+    verbosity: detailed
+  logging/unknown:
+    loglevel: trace
+`,
+			},
+		}}
+	existing.Status.Version = "0.71.0"
+
+	currentV := version.Get()
+	currentV.Collector = "0.72.0"
+
+	// test
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+	assert.NoError(t, err)
+
+	// verify
+	assert.Equal(t, `exporters:
+  logging:
+    verbosity: detailed
+  logging/unknown: {}
+  logging/verbose:
+    verbosity: detailed
+`, res.Spec.Agent.Config)
+	assert.Equal(t, []string{
+		"agent: upgrade to v0.72.0 replaced the 'loglevel: debug' field from \"logging\" exporter with 'verbosity: detailed'",
+		"agent: upgrade to v0.72.0 dropped the unknown 'loglevel' \"trace\" from \"logging/unknown\" exporter",
+		"agent: upgrade to v0.72.0 dropped the 'loglevel' field from \"logging/verbose\" exporter, as 'verbosity' is already set",
+		"applied the upgrade step for v0.72.0",
+	}, res.Status.Messages)
+}
+
+func TestLoggingExporterLoglevelToVerbosityInOverlay(t *testing.T) {
+	// prepare
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AgentSpec{
+			Agent: v1alpha1.CollectorSpec{
+				ConfigOverlay: `
+exporters:
+  logging:
+    loglevel: debug
+`,
+			},
+		}}
+	existing.Status.Version = "0.71.0"
+
+	currentV := version.Get()
+	currentV.Collector = "0.72.0"
+
+	// test
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+	assert.NoError(t, err)
+
+	// verify
+	assert.Equal(t, `exporters:
+  logging:
+    verbosity: detailed
+`, res.Spec.Agent.ConfigOverlay)
+	assert.Equal(t, []string{
+		"agent: upgrade to v0.72.0 replaced the 'loglevel: debug' field from \"logging\" exporter with 'verbosity: detailed' in the config overlay",
+		"applied the upgrade step for v0.72.0",
+	}, res.Status.Messages)
+}
+
+func TestObserverRulesToExpressions(t *testing.T) {
+	// prepare
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AgentSpec{
+			Agent: v1alpha1.CollectorSpec{
+				Config: `
+receivers:
+  receiver_creator:
+    watch_observers: [k8s_observer]
+    receivers:
+      redis:
+        rule: type.port && pod.name matches "redis"
+      kubeletstats:
+        rule: type == "k8s.node"
+`,
+			},
+		}}
+	existing.Status.Version = "0.71.0"
+
+	currentV := version.Get()
+	currentV.Collector = "0.72.0"
+
+	// test
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+	assert.NoError(t, err)
+
+	// verify
+	assert.Equal(t, `receivers:
+  receiver_creator:
+    receivers:
+      kubeletstats:
+        rule: type == "k8s.node"
+      redis:
+        rule: type == "port" && pod.name matches "redis"
+    watch_observers:
+    - k8s_observer
+`, res.Spec.Agent.Config)
+	assert.Equal(t, []string{
+		"agent: upgrade to v0.72.0 rewrote the legacy rule of \"redis\" in \"receiver_creator\" receiver: type == \"port\" && pod.name matches \"redis\"",
+		"applied the upgrade step for v0.72.0",
+	}, res.Status.Messages)
+}
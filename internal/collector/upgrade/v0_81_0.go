@@ -0,0 +1,41 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+)
+
+func upgrade0_81_0(cl client.Client, spec *v1alpha1.CollectorSpec) ([]string, error) {
+	return migrateConfig(spec, "0.81.0", func(cfg map[interface{}]interface{}) []string {
+		var messages []string
+		for _, name := range componentsOfType(cfg, "processors", "resourcedetection") {
+			processor := componentConfig(cfg, "processors", name)
+
+			// the 'attributes' allow list is no longer accepted, each detector now has its own 'resource_attributes'
+			// setting to enable or disable attributes. There's no safe way to translate one into the other, as the
+			// attributes emitted by each detector differ, so the user has to review it.
+			if _, ok := processor["attributes"]; ok {
+				delete(processor, "attributes")
+				messages = append(messages, fmt.Sprintf("upgrade to v0.81.0 dropped the 'attributes' field from %q processor, use the 'resource_attributes' field of each detector instead", name))
+			}
+		}
+		return messages
+	})
+}
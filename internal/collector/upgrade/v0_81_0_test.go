@@ -0,0 +1,70 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/upgrade"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/version"
+)
+
+func TestResourceDetectionAttributesDrop(t *testing.T) {
+	// prepare
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AgentSpec{
+			Agent: v1alpha1.CollectorSpec{
+				Config: `
+processors:
+  resourcedetection:
+    detectors: [system]
+    attributes: [host.name]
+  resourcedetection/internal:
+    detectors: [env]
+`,
+			},
+		}}
+	existing.Status.Version = "0.80.0"
+
+	currentV := version.Get()
+	currentV.Collector = "0.81.0"
+
+	// test
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+	assert.NoError(t, err)
+
+	// verify
+	assert.Equal(t, `processors:
+  resourcedetection:
+    detectors:
+    - system
+  resourcedetection/internal:
+    detectors:
+    - env
+`, res.Spec.Agent.Config)
+	assert.Equal(t, []string{
+		"agent: upgrade to v0.81.0 dropped the 'attributes' field from \"resourcedetection\" processor, use the 'resource_attributes' field of each detector instead",
+		"applied the upgrade step for v0.81.0",
+	}, res.Status.Messages)
+}
@@ -0,0 +1,40 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+)
+
+func upgrade0_86_0(cl client.Client, spec *v1alpha1.CollectorSpec) ([]string, error) {
+	return migrateConfig(spec, "0.86.0", func(cfg map[interface{}]interface{}) []string {
+		var messages []string
+		for _, name := range componentsOfType(cfg, "exporters", "logging") {
+			// the logging exporter is deprecated in favor of the debug exporter, which takes the same settings
+			replacement := "debug" + strings.TrimPrefix(name, "logging")
+			if err := renameComponent(cfg, "exporters", name, replacement); err != nil {
+				messages = append(messages, fmt.Sprintf("upgrade to v0.86.0 couldn't replace the %q exporter with %q: %v", name, replacement, err))
+				continue
+			}
+			messages = append(messages, fmt.Sprintf("upgrade to v0.86.0 replaced the %q exporter with %q", name, replacement))
+		}
+		return messages
+	})
+}
@@ -0,0 +1,129 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/upgrade"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/version"
+)
+
+func TestLoggingExporterRenamedToDebug(t *testing.T) {
+	// prepare
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AgentSpec{
+			Agent: v1alpha1.CollectorSpec{
+				Config: `
+receivers:
+  otlp:
+exporters:
+  logging:
+    verbosity: detailed
+  logging/basic:
+  otlp:
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlp, logging]
+    logs:
+      receivers: [otlp]
+      exporters: [logging/basic]
+`,
+			},
+		}}
+	existing.Status.Version = "0.85.0"
+
+	currentV := version.Get()
+	currentV.Collector = "0.86.0"
+
+	// test
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+	assert.NoError(t, err)
+
+	// verify
+	assert.Equal(t, `exporters:
+  debug:
+    verbosity: detailed
+  debug/basic: null
+  otlp: null
+receivers:
+  otlp: null
+service:
+  pipelines:
+    logs:
+      exporters:
+      - debug/basic
+      receivers:
+      - otlp
+    traces:
+      exporters:
+      - otlp
+      - debug
+      receivers:
+      - otlp
+`, res.Spec.Agent.Config)
+	assert.Equal(t, []string{
+		"agent: upgrade to v0.86.0 replaced the \"logging\" exporter with \"debug\"",
+		"agent: upgrade to v0.86.0 replaced the \"logging/basic\" exporter with \"debug/basic\"",
+		"applied the upgrade step for v0.86.0",
+	}, res.Status.Messages)
+}
+
+func TestLoggingExporterNotRenamedOnConflict(t *testing.T) {
+	// prepare
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AgentSpec{
+			Agent: v1alpha1.CollectorSpec{
+				Config: `
+exporters:
+  logging:
+  debug:
+`,
+			},
+		}}
+	existing.Status.Version = "0.85.0"
+
+	currentV := version.Get()
+	currentV.Collector = "0.86.0"
+
+	// test
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+	assert.NoError(t, err)
+
+	// verify
+	assert.Equal(t, `exporters:
+  debug: null
+  logging: null
+`, res.Spec.Agent.Config)
+	assert.Equal(t, []string{
+		"agent: upgrade to v0.86.0 couldn't replace the \"logging\" exporter with \"debug\": the exporters \"debug\" is already defined",
+		"applied the upgrade step for v0.86.0",
+	}, res.Status.Messages)
+}
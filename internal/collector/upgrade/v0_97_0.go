@@ -0,0 +1,36 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+)
+
+func upgrade0_97_0(cl client.Client, spec *v1alpha1.CollectorSpec) ([]string, error) {
+	return migrateConfig(spec, "0.97.0", func(cfg map[interface{}]interface{}) []string {
+		var messages []string
+		for _, name := range componentsOfType(cfg, "extensions", "memory_ballast") {
+			// the memory ballast is superseded by the GOMEMLIMIT set from SPLUNK_MEMORY_TOTAL_MIB, and the
+			// memory_limiter processor keeps handling the memory pressure
+			removeComponent(cfg, "extensions", name)
+			messages = append(messages, fmt.Sprintf("upgrade to v0.97.0 removed the deprecated %q extension", name))
+		}
+		return messages
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright Splunk Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/upgrade"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/version"
+)
+
+func TestMemoryBallastExtensionRemoved(t *testing.T) {
+	// prepare
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AgentSpec{
+			Agent: v1alpha1.CollectorSpec{
+				Config: `
+extensions:
+  health_check:
+  memory_ballast:
+    size_mib: ${SPLUNK_BALLAST_SIZE_MIB}
+service:
+  extensions: [health_check, memory_ballast]
+`,
+			},
+		}}
+	existing.Status.Version = "0.96.0"
+
+	currentV := version.Get()
+	currentV.Collector = "0.97.0"
+
+	// test
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+	assert.NoError(t, err)
+
+	// verify
+	assert.Equal(t, `extensions:
+  health_check: null
+service:
+  extensions:
+  - health_check
+`, res.Spec.Agent.Config)
+	assert.Equal(t, []string{
+		"agent: upgrade to v0.97.0 removed the deprecated \"memory_ballast\" extension",
+		"applied the upgrade step for v0.97.0",
+	}, res.Status.Messages)
+}
+
+func TestMemoryBallastExtensionRemovedFromOverlay(t *testing.T) {
+	// prepare
+	existing := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AgentSpec{
+			Gateway: v1alpha1.CollectorSpec{
+				Config: `
+extensions:
+  health_check:
+service:
+  extensions: [health_check]
+`,
+				ConfigOverlay: `
+extensions:
+  memory_ballast:
+    size_mib: 512
+service:
+  extensions: [health_check, memory_ballast]
+`,
+			},
+		}}
+	existing.Status.Version = "0.96.0"
+
+	currentV := version.Get()
+	currentV.Collector = "0.97.0"
+
+	// test
+	res, err := upgrade.ManagedInstance(context.Background(), logger, currentV, nil, existing)
+	assert.NoError(t, err)
+
+	// verify
+	assert.Equal(t, existing.Spec.Gateway.Config, res.Spec.Gateway.Config)
+	assert.Equal(t, `extensions: {}
+service:
+  extensions:
+  - health_check
+`, res.Spec.Gateway.ConfigOverlay)
+	assert.Equal(t, []string{
+		"gateway: upgrade to v0.97.0 removed the deprecated \"memory_ballast\" extension in the config overlay",
+		"applied the upgrade step for v0.97.0",
+	}, res.Status.Messages)
+}
@@ -22,7 +22,9 @@ import (
 	"github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
 )
 
-type upgradeFunc func(cl client.Client, otelcol *v1alpha1.Agent) (*v1alpha1.Agent, error)
+// upgradeFunc migrates a single collector spec in place, returning a message for each change it made.
+// Every registered step is applied to the agent, cluster receiver and gateway specs, as they all run the same collector.
+type upgradeFunc func(cl client.Client, spec *v1alpha1.CollectorSpec) ([]string, error)
 
 type otelcolVersion struct {
 	semver.Version
@@ -35,6 +37,26 @@ var (
 			Version: *semver.MustParse("0.31.0"),
 			upgrade: upgrade0_31_0,
 		},
+		{
+			Version: *semver.MustParse("0.72.0"),
+			upgrade: upgrade0_72_0,
+		},
+		{
+			Version: *semver.MustParse("0.81.0"),
+			upgrade: upgrade0_81_0,
+		},
+		{
+			Version: *semver.MustParse("0.86.0"),
+			upgrade: upgrade0_86_0,
+		},
+		{
+			Version: *semver.MustParse("0.97.0"),
+			upgrade: upgrade0_97_0,
+		},
+		{
+			Version: *semver.MustParse("0.116.0"),
+			upgrade: upgrade0_116_0,
+		},
 	}
 
 	// Latest represents the latest version that we need to upgrade. This is not necessarily the latest known version.
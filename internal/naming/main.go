@@ -52,30 +52,44 @@ func ClusterReceiver(otelcol v1alpha1.Agent) string {
 	return fmt.Sprintf("%s-cluster-receiver", otelcol.Name)
 }
 
-// HeadlessService builds the name for the headless service based on the instance.
-func HeadlessService(otelcol v1alpha1.Agent) string {
-	return fmt.Sprintf("%s-headless", Service(otelcol))
+// HeadlessService builds the name for the headless service of the given kind of collector.
+func HeadlessService(otelcol v1alpha1.Agent, kind string) string {
+	return fmt.Sprintf("%s-headless", Service(otelcol, kind))
 }
 
-// MonitoringService builds the name for the monitoring service based on the instance.
-func MonitoringService(otelcol v1alpha1.Agent) string {
-	return fmt.Sprintf("%s-monitoring", Service(otelcol))
+// MonitoringService builds the name for the monitoring service of the given kind of collector.
+func MonitoringService(otelcol v1alpha1.Agent, kind string) string {
+	return fmt.Sprintf("%s-monitoring", Service(otelcol, kind))
 }
 
-// Service builds the service name based on the instance.
-func Service(otelcol v1alpha1.Agent) string {
-	return fmt.Sprintf("%s-collector", otelcol.Name)
+// Service builds the name for the service of the given kind of collector. The gateway service keeps the name it had
+// before the other collectors could be exposed, as instrumented applications send their data to it.
+func Service(otelcol v1alpha1.Agent, kind string) string {
+	if kind == "gateway" {
+		return fmt.Sprintf("%s-collector", otelcol.Name)
+	}
+	return fmt.Sprintf("%s-%s", otelcol.Name, kind)
 }
 
-// ServiceAccount builds the service account name based on the instance.
-func ServiceAccount(otelcol v1alpha1.Agent) string {
-	// TODO(splunk): create separate accounts for agent, clusterreceiver
-	// and gateway.
-	// return fmt.Sprintf("%s-account", otelcol.Name)
-	return "splunk-otel-operator-account"
+// ServiceAccount builds the service account name for the given kind of collector.
+func ServiceAccount(otelcol v1alpha1.Agent, kind string) string {
+	return fmt.Sprintf("%s-%s", otelcol.Name, kind)
 }
 
-// Namespace builds the namespace name based on the instance.
-func Namespace(otelcol v1alpha1.Agent) string {
-	return "splunk-otel-operator-system"
+// ClusterRole builds the name for the cluster role and cluster role binding of the given kind of collector. Both are
+// cluster-scoped, so the name includes the namespace of the instance, separated by a dot: namespaces can't contain
+// one, so two instances never share a name.
+func ClusterRole(otelcol v1alpha1.Agent, kind string) string {
+	return fmt.Sprintf("%s.%s-%s", otelcol.Namespace, otelcol.Name, kind)
+}
+
+// Role builds the name for the role and role binding granting the given kind of collector what it needs in the
+// namespace of the instance.
+func Role(otelcol v1alpha1.Agent, kind string) string {
+	return fmt.Sprintf("%s-%s", otelcol.Name, kind)
+}
+
+// UpgradeReport builds the name for the config map holding the dry-run upgrade report of the instance.
+func UpgradeReport(otelcol v1alpha1.Agent) string {
+	return fmt.Sprintf("%s-upgrade-report", otelcol.Name)
 }
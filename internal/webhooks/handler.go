@@ -10,6 +10,8 @@ import (
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -30,6 +32,18 @@ const (
 	envOTELTracesExporter       = "OTEL_TRACES_EXPORTER"
 	envOTELResourceAttrs        = "OTEL_RESOURCE_ATTRIBUTES"
 	envJavaToolsOptions         = "JAVA_TOOL_OPTIONS"
+	envNodeOptions              = "NODE_OPTIONS"
+	envPythonPath               = "PYTHONPATH"
+	envOTELPythonDistro         = "OTEL_PYTHON_DISTRO"
+	envOTELPythonConfigurator   = "OTEL_PYTHON_CONFIGURATOR"
+	envCoreCLREnableProfiling   = "CORECLR_ENABLE_PROFILING"
+	envCoreCLRProfiler          = "CORECLR_PROFILER"
+	envCoreCLRProfilerPath      = "CORECLR_PROFILER_PATH"
+	envDotNetAdditionalDeps     = "DOTNET_ADDITIONAL_DEPS"
+	envDotNetSharedStore        = "DOTNET_SHARED_STORE"
+	envDotNetStartupHooks       = "DOTNET_STARTUP_HOOKS"
+	envOTELDotNetAutoHome       = "OTEL_DOTNET_AUTO_HOME"
+	envOTELDotNetAutoPlugins    = "OTEL_DOTNET_AUTO_PLUGINS"
 
 	volumeName        = "splunk-instrumentation"
 	initContainerName = "splunk-instrumentation"
@@ -37,13 +51,53 @@ const (
 	exporterOTLP      = "otlp"
 	exporterJaeger    = "jaeger-thrift-splunk"
 
+	nodeJSVolumeName        = "splunk-instrumentation-nodejs"
+	nodeJSInitContainerName = "splunk-instrumentation-nodejs"
+	nodeJSMountPath         = "/splunk-nodejs"
+	nodeJSRequireArgument   = " --require " + nodeJSMountPath + "/node_modules/@splunk/otel/instrument"
+
+	pythonVolumeName        = "splunk-instrumentation-python"
+	pythonInitContainerName = "splunk-instrumentation-python"
+	pythonMountPath         = "/splunk-python"
+	pythonPath              = pythonMountPath + "/opentelemetry/instrumentation/auto_instrumentation:" + pythonMountPath
+	pythonDistro            = "splunk_distro"
+	pythonConfigurator      = "splunk_configurator"
+
+	dotNetVolumeName        = "splunk-instrumentation-dotnet"
+	dotNetInitContainerName = "splunk-instrumentation-dotnet"
+	dotNetMountPath         = "/splunk-dotnet"
+	dotNetProfilerID        = "{918728DD-259F-4A6A-AC2B-B85E1B658318}"
+	dotNetPlugins           = "Splunk.OpenTelemetry.AutoInstrumentation.Plugin, Splunk.OpenTelemetry.AutoInstrumentation"
+	dotNetRuntimeGlibc      = "linux-x64"
+	dotNetRuntimeMusl       = "linux-musl-x64"
+
 	annotationJava   = "otel.splunk.com/inject-java"
+	annotationNodeJS = "otel.splunk.com/inject-nodejs"
+	annotationPython = "otel.splunk.com/inject-python"
+	annotationDotNet = "otel.splunk.com/inject-dotnet"
 	annotationConfig = "otel.splunk.com/inject-config"
 	annotationStatus = "otel.splunk.com/injection-status"
 	annotationReason = "otel.splunk.com/injection-reason"
+
+	// annotationContainerNames lists the containers to inject into, comma separated, "*" for all of them. Only the
+	// first container is injected into when unset.
+	annotationContainerNames = "otel.splunk.com/container-names"
+
+	// annotationDotNetRuntime selects the build of the .NET profiler matching the C library of the container image
+	annotationDotNetRuntime = "otel.splunk.com/dotnet-runtime"
 )
 
-type injectFn func(ctx context.Context, cfg config, pod corev1.Pod, ns corev1.Namespace) (corev1.Pod, error)
+// unselectableNamespaces are never matched by the namespace selectors of the Agent, not even by an empty selector
+// selecting all the namespaces: the system namespaces and the one of the operator. Their pods are still instrumented
+// when they, or their namespace, are annotated.
+var unselectableNamespaces = map[string]bool{
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+	operatorNamespace: true,
+}
+
+type injectFn func(ctx context.Context, cfg config, pod corev1.Pod, idx int, ns corev1.Namespace) (corev1.Pod, error)
 
 type handler struct {
 	client    client.Client
@@ -53,9 +107,12 @@ type handler struct {
 }
 
 type config struct {
-	exporter  string
-	endpoint  string
-	javaImage string
+	exporter    string
+	endpoint    string
+	javaImage   string
+	nodeJSImage string
+	pythonImage string
+	dotNetImage string
 }
 
 // NewHandler creates a new WebhookHandler.
@@ -66,6 +123,9 @@ func NewHandler(logger logr.Logger, cl client.Client) admission.Handler {
 	}
 	h.injectMap = map[string]injectFn{
 		annotationJava:   h.injectJava,
+		annotationNodeJS: h.injectNodeJS,
+		annotationPython: h.injectPython,
+		annotationDotNet: h.injectDotNet,
 		annotationConfig: h.injectConfig,
 	}
 	return h
@@ -98,13 +158,25 @@ func (h *handler) Handle(ctx context.Context, req admission.Request) admission.R
 		pod.Annotations = map[string]string{}
 	}
 
-	injectFunctions := []injectFn{}
-	for ann, fn := range h.injectMap {
-		if strings.EqualFold(pod.Annotations[ann], "true") {
-			injectFunctions = append(injectFunctions, fn)
+	// we use the req.Namespace here because the pod might have not been created yet
+	ns := corev1.Namespace{}
+	err = h.client.Get(ctx, types.NamespacedName{Name: req.Namespace, Namespace: ""}, &ns)
+	if err != nil {
+		h.logger.Error(err, "unable to get pod namespace", "namespace", req.Namespace)
+		if len(h.injectFunctions(pod, corev1.Namespace{}, nil)) == 0 {
+			// pods that didn't opt in themselves aren't held back
+			return admission.Allowed("")
 		}
+		return admission.Errored(http.StatusBadRequest, err)
 	}
 
+	spec, specErr := h.getAgentSpec(ctx)
+	var selectors map[string]labels.Selector
+	if specErr == nil {
+		selectors = h.namespaceSelectors(spec)
+	}
+
+	injectFunctions := h.injectFunctions(pod, ns, selectors)
 	if len(injectFunctions) == 0 {
 		return admission.Allowed("")
 	}
@@ -114,31 +186,145 @@ func (h *handler) Handle(ctx context.Context, req admission.Request) admission.R
 		return admission.Allowed("")
 	}
 
-	// we use the req.Namespace here because the pod might have not been created yet
-	ns := corev1.Namespace{}
-	err = h.client.Get(ctx, types.NamespacedName{Name: req.Namespace, Namespace: ""}, &ns)
-	if err != nil {
-		h.logger.Error(err, "unable to get pod namespace", "namespace", req.Namespace)
-		return admission.Errored(http.StatusBadRequest, err)
-	}
-
-	spec, err := h.getAgentSpec(ctx)
-	if err != nil {
+	if specErr != nil {
 		msg := "unable to get splunk agent spec. make sure SplunkOtelAgent is deployed"
-		h.logger.Error(err, msg)
+		h.logger.Error(specErr, msg)
 		return h.patch(req, pod, errors.New(msg))
 	}
 
 	cfg := configFromSpec(spec)
 
-	for _, fn := range injectFunctions {
-		pod, err = fn(ctx, cfg, pod, ns)
+	containers, err := targetContainers(pod)
+	if err != nil {
+		return h.patch(req, pod, err)
+	}
+
+	pod, failures := injectContainers(ctx, cfg, pod, containers, ns, injectFunctions)
+	switch {
+	case len(failures) == len(containers):
+		return h.patch(req, pod, errors.New(strings.Join(failures, "; ")))
+	case len(failures) > 0:
+		pod.Annotations[annotationStatus] = "partial"
+		pod.Annotations[annotationReason] = strings.Join(failures, "; ")
+	default:
+		pod.Annotations[annotationStatus] = "success"
+	}
+	return h.patch(req, pod, nil)
+}
+
+// injectFunctions returns the inject functions enabled for the pod. Each injection is enabled by the inject
+// annotation of the pod, or else by the one of its namespace, or else when the namespace matches the selector of the
+// Agent for it, unless it's a system namespace or the one of the operator. The annotations disable the injection when set to anything but "true", like "false".
+func (h *handler) injectFunctions(pod corev1.Pod, ns corev1.Namespace, selectors map[string]labels.Selector) []injectFn {
+	// the collectors deployed by the operator are only injected into when asked explicitly
+	managed := pod.Labels["app.kubernetes.io/managed-by"] == "splunk-otel-collector-operator"
+
+	injectFunctions := []injectFn{}
+	for ann, fn := range h.injectMap {
+		var enabled bool
+		if value, ok := pod.Annotations[ann]; ok {
+			enabled = strings.EqualFold(value, "true")
+		} else if value, ok := ns.Annotations[ann]; ok {
+			enabled = !managed && strings.EqualFold(value, "true")
+		} else if selector, ok := selectors[ann]; ok {
+			enabled = !managed && !unselectableNamespaces[ns.Name] && selector.Matches(labels.Set(ns.Labels))
+		}
+
+		if enabled {
+			injectFunctions = append(injectFunctions, fn)
+		}
+	}
+	return injectFunctions
+}
+
+// namespaceSelectors returns the namespace selectors of the auto-instrumentation agents, by inject annotation.
+func (h *handler) namespaceSelectors(spec *v1alpha1.AgentSpec) map[string]labels.Selector {
+	selectors := map[string]labels.Selector{}
+	for ann, agent := range map[string]v1alpha1.AutoInstrumentation{
+		annotationJava:   spec.Instrumentation.Java,
+		annotationNodeJS: spec.Instrumentation.NodeJS,
+		annotationPython: spec.Instrumentation.Python,
+		annotationDotNet: spec.Instrumentation.DotNet,
+	} {
+		if agent.NamespaceSelector == nil {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(agent.NamespaceSelector)
 		if err != nil {
-			return h.patch(req, pod, err)
+			// already rejected by the validating webhook of the Agent
+			h.logger.Error(err, "invalid namespace selector", "annotation", ann)
+			continue
 		}
+		selectors[ann] = selector
 	}
-	pod.Annotations[annotationStatus] = "success"
-	return h.patch(req, pod, nil)
+	return selectors
+}
+
+// injectContainers runs the inject functions for each of the given containers independently: a container the injection
+// failed for is left as it was, and the failure is returned.
+func injectContainers(ctx context.Context, cfg config, pod corev1.Pod, containers []int, ns corev1.Namespace, injectFunctions []injectFn) (corev1.Pod, []string) {
+	var failures []string
+	for _, idx := range containers {
+		injected := *pod.DeepCopy()
+		var err error
+		for _, fn := range injectFunctions {
+			injected, err = fn(ctx, cfg, injected, idx, ns)
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("container %s: %v", pod.Spec.Containers[idx].Name, err))
+			continue
+		}
+		pod = injected
+	}
+	return pod, failures
+}
+
+// targetContainers returns the indexes of the containers of the pod to inject into, as listed by the container-names
+// annotation.
+func targetContainers(pod corev1.Pod) ([]int, error) {
+	names := strings.TrimSpace(pod.Annotations[annotationContainerNames])
+	switch names {
+	case "":
+		return []int{0}, nil
+	case "*":
+		all := make([]int, len(pod.Spec.Containers))
+		for i := range pod.Spec.Containers {
+			all[i] = i
+		}
+		return all, nil
+	}
+
+	var indexes []int
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		idx := -1
+		for i, container := range pod.Spec.Containers {
+			if container.Name == name {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			return nil, fmt.Errorf("the container %q listed by the %s annotation doesn't exist", name, annotationContainerNames)
+		}
+		if !containsIndex(indexes, idx) {
+			indexes = append(indexes, idx)
+		}
+	}
+	return indexes, nil
+}
+
+func containsIndex(indexes []int, idx int) bool {
+	for _, i := range indexes {
+		if i == idx {
+			return true
+		}
+	}
+	return false
 }
 
 func configFromSpec(spec *v1alpha1.AgentSpec) config {
@@ -158,43 +344,29 @@ func configFromSpec(spec *v1alpha1.AgentSpec) config {
 	}
 
 	cfg.javaImage = spec.Instrumentation.Java.Image
+	cfg.nodeJSImage = spec.Instrumentation.NodeJS.Image
+	cfg.pythonImage = spec.Instrumentation.Python.Image
+	cfg.dotNetImage = spec.Instrumentation.DotNet.Image
 
 	return cfg
 }
 
-func (h *handler) injectJava(ctx context.Context, cfg config, pod corev1.Pod, ns corev1.Namespace) (corev1.Pod, error) {
-	pod, err := h.injectConfig(ctx, cfg, pod, ns)
+func (h *handler) injectJava(ctx context.Context, cfg config, pod corev1.Pod, idx int, ns corev1.Namespace) (corev1.Pod, error) {
+	pod, err := h.injectConfig(ctx, cfg, pod, idx, ns)
 	if err != nil {
 		return pod, err
 	}
 
-	container := &pod.Spec.Containers[0]
-	idx := getIndexOfEnv(container.Env, envJavaToolsOptions)
-	if idx == -1 {
-		container.Env = append(container.Env, corev1.EnvVar{
-			Name:  envJavaToolsOptions,
-			Value: javaJVMArgument,
-		})
-	} else {
-		if container.Env[idx].ValueFrom != nil {
-			msg := fmt.Sprintf("Skipping javaagent injection, the container defines JAVA_TOOL_OPTIONS env var value via ValueFrom for container %s", container.Name)
-			h.logger.Info(msg)
-			return pod, errors.New(msg)
-		}
-		container.Env[idx].Value = container.Env[idx].Value + javaJVMArgument
+	container := &pod.Spec.Containers[idx]
+	if err := h.appendToEnv(container, envJavaToolsOptions, javaJVMArgument, "javaagent"); err != nil {
+		return pod, err
 	}
 	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
 		Name:      volumeName,
 		MountPath: "/splunk",
 	})
 
-	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
-		Name: volumeName,
-		VolumeSource: corev1.VolumeSource{
-			EmptyDir: &corev1.EmptyDirVolumeSource{},
-		}})
-
-	pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{
+	addInstrumentation(&pod, volumeName, corev1.Container{
 		Name:    initContainerName,
 		Image:   cfg.javaImage,
 		Command: []string{"cp", "/splunk-otel-javaagent-all.jar", "/splunk/splunk-otel-javaagent-all.jar"},
@@ -207,10 +379,201 @@ func (h *handler) injectJava(ctx context.Context, cfg config, pod corev1.Pod, ns
 	return pod, nil
 }
 
-func (h *handler) injectConfig(ctx context.Context, cfg config, pod corev1.Pod, ns corev1.Namespace) (corev1.Pod, error) {
+func (h *handler) injectNodeJS(ctx context.Context, cfg config, pod corev1.Pod, idx int, ns corev1.Namespace) (corev1.Pod, error) {
+	pod, err := h.injectConfig(ctx, cfg, pod, idx, ns)
+	if err != nil {
+		return pod, err
+	}
 
-	container := &pod.Spec.Containers[0]
-	resourceAttrs, resourceEnvIdx := h.createResourceMap(ctx, ns, pod)
+	container := &pod.Spec.Containers[idx]
+	if err := h.appendToEnv(container, envNodeOptions, nodeJSRequireArgument, "Node.js agent"); err != nil {
+		return pod, err
+	}
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      nodeJSVolumeName,
+		MountPath: nodeJSMountPath,
+	})
+
+	// the image ships the Splunk OTel JS distribution along with its dependencies
+	addInstrumentation(&pod, nodeJSVolumeName, corev1.Container{
+		Name:    nodeJSInitContainerName,
+		Image:   cfg.nodeJSImage,
+		Command: []string{"cp", "-a", "/autoinstrumentation/.", nodeJSMountPath},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      nodeJSVolumeName,
+			MountPath: nodeJSMountPath,
+		}},
+	})
+
+	return pod, nil
+}
+
+func (h *handler) injectPython(ctx context.Context, cfg config, pod corev1.Pod, idx int, ns corev1.Namespace) (corev1.Pod, error) {
+	pod, err := h.injectConfig(ctx, cfg, pod, idx, ns)
+	if err != nil {
+		return pod, err
+	}
+
+	container := &pod.Spec.Containers[idx]
+	// the sitecustomize module of the auto_instrumentation package starts the instrumentation before the application
+	if err := h.prependToPath(container, envPythonPath, pythonPath, "Python agent"); err != nil {
+		return pod, err
+	}
+	// the Splunk distro is picked even if the application ships other distros, unless told otherwise
+	setEnvIfUnset(container, envOTELPythonDistro, pythonDistro)
+	setEnvIfUnset(container, envOTELPythonConfigurator, pythonConfigurator)
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      pythonVolumeName,
+		MountPath: pythonMountPath,
+	})
+
+	// the image ships the Splunk OTel Python packages along with their dependencies
+	addInstrumentation(&pod, pythonVolumeName, corev1.Container{
+		Name:    pythonInitContainerName,
+		Image:   cfg.pythonImage,
+		Command: []string{"cp", "-a", "/autoinstrumentation/.", pythonMountPath},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      pythonVolumeName,
+			MountPath: pythonMountPath,
+		}},
+	})
+
+	return pod, nil
+}
+
+func (h *handler) injectDotNet(ctx context.Context, cfg config, pod corev1.Pod, idx int, ns corev1.Namespace) (corev1.Pod, error) {
+	runtime := pod.Annotations[annotationDotNetRuntime]
+	switch runtime {
+	case "":
+		runtime = dotNetRuntimeGlibc
+	case dotNetRuntimeGlibc, dotNetRuntimeMusl:
+	default:
+		return pod, fmt.Errorf("unsupported %s annotation %q, it should be %q or %q", annotationDotNetRuntime, runtime, dotNetRuntimeGlibc, dotNetRuntimeMusl)
+	}
+
+	pod, err := h.injectConfig(ctx, cfg, pod, idx, ns)
+	if err != nil {
+		return pod, err
+	}
+
+	container := &pod.Spec.Containers[idx]
+	// the CLR only loads a single profiler
+	for _, name := range []string{envCoreCLREnableProfiling, envCoreCLRProfiler, envCoreCLRProfilerPath} {
+		if getIndexOfEnv(container.Env, name) != -1 {
+			msg := fmt.Sprintf("Skipping .NET agent injection, the container defines %s env var for container %s", name, container.Name)
+			h.logger.Info(msg)
+			return pod, errors.New(msg)
+		}
+	}
+	container.Env = append(container.Env,
+		corev1.EnvVar{Name: envCoreCLREnableProfiling, Value: "1"},
+		corev1.EnvVar{Name: envCoreCLRProfiler, Value: dotNetProfilerID},
+		corev1.EnvVar{Name: envCoreCLRProfilerPath, Value: dotNetMountPath + "/" + runtime + "/OpenTelemetry.AutoInstrumentation.Native.so"},
+	)
+	for _, env := range []corev1.EnvVar{
+		{Name: envDotNetAdditionalDeps, Value: dotNetMountPath + "/AdditionalDeps"},
+		{Name: envDotNetSharedStore, Value: dotNetMountPath + "/store"},
+		{Name: envDotNetStartupHooks, Value: dotNetMountPath + "/net/OpenTelemetry.AutoInstrumentation.StartupHook.dll"},
+	} {
+		if err := h.prependToPath(container, env.Name, env.Value, ".NET agent"); err != nil {
+			return pod, err
+		}
+	}
+	setEnvIfUnset(container, envOTELDotNetAutoHome, dotNetMountPath)
+	setEnvIfUnset(container, envOTELDotNetAutoPlugins, dotNetPlugins)
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      dotNetVolumeName,
+		MountPath: dotNetMountPath,
+	})
+
+	// the image ships the Splunk .NET instrumentation for both the glibc and musl runtimes
+	addInstrumentation(&pod, dotNetVolumeName, corev1.Container{
+		Name:    dotNetInitContainerName,
+		Image:   cfg.dotNetImage,
+		Command: []string{"cp", "-a", "/autoinstrumentation/.", dotNetMountPath},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      dotNetVolumeName,
+			MountPath: dotNetMountPath,
+		}},
+	})
+
+	return pod, nil
+}
+
+// addInstrumentation adds the volume the init container copies an instrumentation agent to, along with the init
+// container. Both are shared by the containers the agent is injected into, so they're only added once.
+func addInstrumentation(pod *corev1.Pod, volume string, initContainer corev1.Container) {
+	for _, v := range pod.Spec.Volumes {
+		if v.Name == volume {
+			return
+		}
+	}
+
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: volume,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		}})
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, initContainer)
+}
+
+// appendToEnv appends the given value to the env var of the container, setting it when undefined. Env vars defined
+// with ValueFrom can't be extended, so the injection of the agent is skipped for them.
+func (h *handler) appendToEnv(container *corev1.Container, name, value, agent string) error {
+	idx := getIndexOfEnv(container.Env, name)
+	if idx == -1 {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  name,
+			Value: value,
+		})
+		return nil
+	}
+
+	if container.Env[idx].ValueFrom != nil {
+		msg := fmt.Sprintf("Skipping %s injection, the container defines %s env var value via ValueFrom for container %s", agent, name, container.Name)
+		h.logger.Info(msg)
+		return errors.New(msg)
+	}
+	container.Env[idx].Value = container.Env[idx].Value + value
+	return nil
+}
+
+// prependToPath prepends the given paths to the path list held by the env var of the container, setting it when
+// undefined. Env vars defined with ValueFrom can't be extended, so the injection of the agent is skipped for them.
+func (h *handler) prependToPath(container *corev1.Container, name, paths, agent string) error {
+	idx := getIndexOfEnv(container.Env, name)
+	if idx == -1 {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  name,
+			Value: paths,
+		})
+		return nil
+	}
+
+	if container.Env[idx].ValueFrom != nil {
+		msg := fmt.Sprintf("Skipping %s injection, the container defines %s env var value via ValueFrom for container %s", agent, name, container.Name)
+		h.logger.Info(msg)
+		return errors.New(msg)
+	}
+	if container.Env[idx].Value == "" {
+		container.Env[idx].Value = paths
+	} else {
+		container.Env[idx].Value = paths + ":" + container.Env[idx].Value
+	}
+	return nil
+}
+
+// setEnvIfUnset sets the env var of the container, unless the container already defines it.
+func setEnvIfUnset(container *corev1.Container, name, value string) {
+	if getIndexOfEnv(container.Env, name) == -1 {
+		container.Env = append(container.Env, corev1.EnvVar{Name: name, Value: value})
+	}
+}
+
+func (h *handler) injectConfig(ctx context.Context, cfg config, pod corev1.Pod, idx int, ns corev1.Namespace) (corev1.Pod, error) {
+
+	container := &pod.Spec.Containers[idx]
+	resourceAttrs, resourceEnvIdx := h.createResourceMap(ctx, ns, pod, idx)
 	// TODO: some attrs such as node name, pod uid could be empty at this stage
 	// so we should use k8s downward API to get read them lazily
 
@@ -220,7 +583,6 @@ func (h *handler) injectConfig(ctx context.Context, cfg config, pod corev1.Pod,
 				FieldPath: "status.hostIP",
 			},
 		}},
-		{Name: envOTELServiceName, Value: serviceName(pod, resourceAttrs)},
 		{Name: envOTELExporterOTLPEndpoint, Value: cfg.endpoint},
 		{Name: envOTELTracesExporter, Value: cfg.exporter},
 		// TODO: add SPLUNK_ACCESS_TOKEN using env from
@@ -230,6 +592,11 @@ func (h *handler) injectConfig(ctx context.Context, cfg config, pod corev1.Pod,
 		// and inject as an environment variable?
 	}
 
+	// the service name set by the user for the container wins
+	if getIndexOfEnv(container.Env, envOTELServiceName) == -1 {
+		newEnv = append(newEnv, corev1.EnvVar{Name: envOTELServiceName, Value: serviceName(pod, idx, resourceAttrs)})
+	}
+
 	resourceEnv := corev1.EnvVar{Name: envOTELResourceAttrs, Value: resourceMapToStr(resourceAttrs)}
 	if resourceEnvIdx > -1 {
 		container.Env[resourceEnvIdx] = resourceEnv
@@ -39,12 +39,16 @@ func TestConfigFromSpec(t *testing.T) {
 					Java: v1alpha1.AutoInstrumentation{
 						Image: "quay.io/signalfx/splunk-otel-instrumentation-java:v1.2.3",
 					},
+					NodeJS: v1alpha1.AutoInstrumentation{
+						Image: "ghcr.io/signalfx/splunk-otel-js/splunk-otel-js:v2.4.1",
+					},
 				},
 			},
 			cfg: config{
-				exporter:  "otlp",
-				endpoint:  "http://$(SPLUNK_OTEL_AGENT):4317",
-				javaImage: "quay.io/signalfx/splunk-otel-instrumentation-java:v1.2.3",
+				exporter:    "otlp",
+				endpoint:    "http://$(SPLUNK_OTEL_AGENT):4317",
+				javaImage:   "quay.io/signalfx/splunk-otel-instrumentation-java:v1.2.3",
+				nodeJSImage: "ghcr.io/signalfx/splunk-otel-js/splunk-otel-js:v2.4.1",
 			},
 		},
 		{
@@ -165,7 +169,7 @@ func TestInjectConfig(t *testing.T) {
 		if tc.container != nil {
 			pod.Spec.Containers = append(pod.Spec.Containers, *tc.container)
 		}
-		got, err := h.injectConfig(context.Background(), tc.cfg, pod, ns)
+		got, err := h.injectConfig(context.Background(), tc.cfg, pod, 0, ns)
 
 		if !tc.shouldInject {
 			continue
@@ -233,7 +237,7 @@ func TestInjectJava(t *testing.T) {
 		if tc.container != nil {
 			pod.Spec.Containers = append(pod.Spec.Containers, *tc.container)
 		}
-		got, err := h.injectJava(context.Background(), tc.cfg, pod, ns)
+		got, err := h.injectJava(context.Background(), tc.cfg, pod, 0, ns)
 
 		if !tc.shouldInject {
 			continue
@@ -272,3 +276,451 @@ func TestInjectJava(t *testing.T) {
 		assert.Equal(t, v.MountPath, "/splunk")
 	}
 }
+
+func TestInjectNodeJS(t *testing.T) {
+	cases := []struct {
+		desc        string
+		env         []corev1.EnvVar
+		nodeOptions string
+		err         bool
+	}{
+		{
+			desc:        "without NODE_OPTIONS",
+			nodeOptions: " --require /splunk-nodejs/node_modules/@splunk/otel/instrument",
+		},
+		{
+			desc:        "with NODE_OPTIONS",
+			env:         []corev1.EnvVar{{Name: "NODE_OPTIONS", Value: "--max-old-space-size=4096"}},
+			nodeOptions: "--max-old-space-size=4096 --require /splunk-nodejs/node_modules/@splunk/otel/instrument",
+		},
+		{
+			desc: "with NODE_OPTIONS from a config map",
+			env: []corev1.EnvVar{{Name: "NODE_OPTIONS", ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "node-options"},
+			}}},
+			err: true,
+		},
+	}
+
+	h := &handler{
+		logger: logr.Discard(),
+	}
+	cfg := config{
+		exporter:    "otlp",
+		endpoint:    "localhost",
+		nodeJSImage: "ghcr.io/signalfx/splunk-otel-js/splunk-otel-js:v2.4.1",
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-pod",
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "test", Env: tc.env}}},
+			}
+			got, err := h.injectNodeJS(context.Background(), cfg, pod, 0, corev1.Namespace{})
+
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			gc := got.Spec.Containers[0]
+			assert.Contains(t, gc.Env, corev1.EnvVar{Name: "NODE_OPTIONS", Value: tc.nodeOptions})
+			assert.Contains(t, gc.Env, corev1.EnvVar{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Value: cfg.endpoint})
+			assert.Equal(t, []corev1.VolumeMount{{Name: "splunk-instrumentation-nodejs", MountPath: "/splunk-nodejs"}}, gc.VolumeMounts)
+
+			require.Len(t, got.Spec.Volumes, 1)
+			assert.Equal(t, "splunk-instrumentation-nodejs", got.Spec.Volumes[0].Name)
+			assert.Equal(t, &corev1.EmptyDirVolumeSource{}, got.Spec.Volumes[0].EmptyDir)
+
+			require.Len(t, got.Spec.InitContainers, 1)
+			ic := got.Spec.InitContainers[0]
+			assert.Equal(t, "splunk-instrumentation-nodejs", ic.Name)
+			assert.Equal(t, cfg.nodeJSImage, ic.Image)
+			assert.Equal(t, []string{"cp", "-a", "/autoinstrumentation/.", "/splunk-nodejs"}, ic.Command)
+			assert.Equal(t, []corev1.VolumeMount{{Name: "splunk-instrumentation-nodejs", MountPath: "/splunk-nodejs"}}, ic.VolumeMounts)
+		})
+	}
+}
+
+func TestInjectPython(t *testing.T) {
+	cases := []struct {
+		desc       string
+		env        []corev1.EnvVar
+		pythonPath string
+		distro     string
+		err        bool
+	}{
+		{
+			desc:       "without PYTHONPATH",
+			pythonPath: "/splunk-python/opentelemetry/instrumentation/auto_instrumentation:/splunk-python",
+			distro:     "splunk_distro",
+		},
+		{
+			desc:       "with PYTHONPATH",
+			env:        []corev1.EnvVar{{Name: "PYTHONPATH", Value: "/app/lib"}},
+			pythonPath: "/splunk-python/opentelemetry/instrumentation/auto_instrumentation:/splunk-python:/app/lib",
+			distro:     "splunk_distro",
+		},
+		{
+			desc:       "with OTEL_PYTHON_DISTRO",
+			env:        []corev1.EnvVar{{Name: "OTEL_PYTHON_DISTRO", Value: "my_distro"}},
+			pythonPath: "/splunk-python/opentelemetry/instrumentation/auto_instrumentation:/splunk-python",
+			distro:     "my_distro",
+		},
+		{
+			desc: "with PYTHONPATH from a config map",
+			env: []corev1.EnvVar{{Name: "PYTHONPATH", ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "python-path"},
+			}}},
+			err: true,
+		},
+	}
+
+	h := &handler{
+		logger: logr.Discard(),
+	}
+	cfg := config{
+		exporter:    "otlp",
+		endpoint:    "localhost",
+		pythonImage: "ghcr.io/signalfx/splunk-otel-python/splunk-otel-python:v1.11.0",
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-pod",
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "test", Env: tc.env}}},
+			}
+			got, err := h.injectPython(context.Background(), cfg, pod, 0, corev1.Namespace{})
+
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			gc := got.Spec.Containers[0]
+			assert.Contains(t, gc.Env, corev1.EnvVar{Name: "PYTHONPATH", Value: tc.pythonPath})
+			assert.Contains(t, gc.Env, corev1.EnvVar{Name: "OTEL_PYTHON_DISTRO", Value: tc.distro})
+			assert.Contains(t, gc.Env, corev1.EnvVar{Name: "OTEL_PYTHON_CONFIGURATOR", Value: "splunk_configurator"})
+			assert.Contains(t, gc.Env, corev1.EnvVar{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Value: cfg.endpoint})
+			assert.Equal(t, []corev1.VolumeMount{{Name: "splunk-instrumentation-python", MountPath: "/splunk-python"}}, gc.VolumeMounts)
+
+			require.Len(t, got.Spec.Volumes, 1)
+			assert.Equal(t, "splunk-instrumentation-python", got.Spec.Volumes[0].Name)
+
+			require.Len(t, got.Spec.InitContainers, 1)
+			ic := got.Spec.InitContainers[0]
+			assert.Equal(t, "splunk-instrumentation-python", ic.Name)
+			assert.Equal(t, cfg.pythonImage, ic.Image)
+			assert.Equal(t, []string{"cp", "-a", "/autoinstrumentation/.", "/splunk-python"}, ic.Command)
+			assert.Equal(t, []corev1.VolumeMount{{Name: "splunk-instrumentation-python", MountPath: "/splunk-python"}}, ic.VolumeMounts)
+		})
+	}
+}
+
+func TestInjectDotNet(t *testing.T) {
+	cases := []struct {
+		desc         string
+		annotations  map[string]string
+		env          []corev1.EnvVar
+		profilerPath string
+		startupHooks string
+		err          string
+	}{
+		{
+			desc:         "glibc by default",
+			profilerPath: "/splunk-dotnet/linux-x64/OpenTelemetry.AutoInstrumentation.Native.so",
+			startupHooks: "/splunk-dotnet/net/OpenTelemetry.AutoInstrumentation.StartupHook.dll",
+		},
+		{
+			desc:         "musl",
+			annotations:  map[string]string{"otel.splunk.com/dotnet-runtime": "linux-musl-x64"},
+			profilerPath: "/splunk-dotnet/linux-musl-x64/OpenTelemetry.AutoInstrumentation.Native.so",
+			startupHooks: "/splunk-dotnet/net/OpenTelemetry.AutoInstrumentation.StartupHook.dll",
+		},
+		{
+			desc:         "with startup hooks",
+			env:          []corev1.EnvVar{{Name: "DOTNET_STARTUP_HOOKS", Value: "/app/Hook.dll"}},
+			profilerPath: "/splunk-dotnet/linux-x64/OpenTelemetry.AutoInstrumentation.Native.so",
+			startupHooks: "/splunk-dotnet/net/OpenTelemetry.AutoInstrumentation.StartupHook.dll:/app/Hook.dll",
+		},
+		{
+			desc:        "unsupported runtime",
+			annotations: map[string]string{"otel.splunk.com/dotnet-runtime": "win-x64"},
+			err:         `unsupported otel.splunk.com/dotnet-runtime annotation "win-x64", it should be "linux-x64" or "linux-musl-x64"`,
+		},
+		{
+			desc: "with another profiler",
+			env:  []corev1.EnvVar{{Name: "CORECLR_PROFILER", Value: "{00000000-0000-0000-0000-000000000000}"}},
+			err:  "Skipping .NET agent injection, the container defines CORECLR_PROFILER env var for container test",
+		},
+	}
+
+	h := &handler{
+		logger: logr.Discard(),
+	}
+	cfg := config{
+		exporter:    "otlp",
+		endpoint:    "localhost",
+		dotNetImage: "ghcr.io/signalfx/splunk-otel-dotnet/splunk-otel-dotnet:v1.0.0",
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Annotations: tc.annotations,
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "test", Env: tc.env}}},
+			}
+			got, err := h.injectDotNet(context.Background(), cfg, pod, 0, corev1.Namespace{})
+
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			gc := got.Spec.Containers[0]
+			assert.Contains(t, gc.Env, corev1.EnvVar{Name: "CORECLR_ENABLE_PROFILING", Value: "1"})
+			assert.Contains(t, gc.Env, corev1.EnvVar{Name: "CORECLR_PROFILER", Value: "{918728DD-259F-4A6A-AC2B-B85E1B658318}"})
+			assert.Contains(t, gc.Env, corev1.EnvVar{Name: "CORECLR_PROFILER_PATH", Value: tc.profilerPath})
+			assert.Contains(t, gc.Env, corev1.EnvVar{Name: "DOTNET_ADDITIONAL_DEPS", Value: "/splunk-dotnet/AdditionalDeps"})
+			assert.Contains(t, gc.Env, corev1.EnvVar{Name: "DOTNET_SHARED_STORE", Value: "/splunk-dotnet/store"})
+			assert.Contains(t, gc.Env, corev1.EnvVar{Name: "DOTNET_STARTUP_HOOKS", Value: tc.startupHooks})
+			assert.Contains(t, gc.Env, corev1.EnvVar{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Value: cfg.endpoint})
+			assert.Equal(t, []corev1.VolumeMount{{Name: "splunk-instrumentation-dotnet", MountPath: "/splunk-dotnet"}}, gc.VolumeMounts)
+
+			require.Len(t, got.Spec.InitContainers, 1)
+			ic := got.Spec.InitContainers[0]
+			assert.Equal(t, "splunk-instrumentation-dotnet", ic.Name)
+			assert.Equal(t, cfg.dotNetImage, ic.Image)
+			assert.Equal(t, []string{"cp", "-a", "/autoinstrumentation/.", "/splunk-dotnet"}, ic.Command)
+		})
+	}
+}
+
+func TestTargetContainers(t *testing.T) {
+	containers := []corev1.Container{{Name: "istio-proxy"}, {Name: "app"}, {Name: "worker"}}
+	cases := []struct {
+		desc       string
+		annotation string
+		indexes    []int
+		err        string
+	}{
+		{
+			desc:    "first container by default",
+			indexes: []int{0},
+		},
+		{
+			desc:       "all containers",
+			annotation: "*",
+			indexes:    []int{0, 1, 2},
+		},
+		{
+			desc:       "listed containers",
+			annotation: "worker, app,worker",
+			indexes:    []int{2, 1},
+		},
+		{
+			desc:       "unknown container",
+			annotation: "app,sidecar",
+			err:        `the container "sidecar" listed by the otel.splunk.com/container-names annotation doesn't exist`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}},
+				Spec:       corev1.PodSpec{Containers: containers},
+			}
+			if tc.annotation != "" {
+				pod.Annotations["otel.splunk.com/container-names"] = tc.annotation
+			}
+
+			indexes, err := targetContainers(pod)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.indexes, indexes)
+		})
+	}
+}
+
+func TestInjectContainers(t *testing.T) {
+	h := &handler{
+		logger: logr.Discard(),
+	}
+	cfg := config{
+		exporter:  "otlp",
+		endpoint:  "localhost",
+		javaImage: "quay.io/signalfx/splunk-otel-instrumentation-java:v2.0",
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-pod",
+			Annotations: map[string]string{annotationContainerNames: "app,worker,batch"},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "istio-proxy"},
+			{Name: "app", Env: []corev1.EnvVar{{Name: "OTEL_SERVICE_NAME", Value: "checkout"}}},
+			{Name: "worker"},
+			{Name: "batch", Env: []corev1.EnvVar{{Name: "JAVA_TOOL_OPTIONS", ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "java-options"},
+			}}}},
+		}},
+	}
+
+	got, failures := injectContainers(context.Background(), cfg, pod, []int{1, 2, 3}, corev1.Namespace{}, []injectFn{h.injectJava})
+
+	assert.Equal(t, []string{"container batch: Skipping javaagent injection, the container defines JAVA_TOOL_OPTIONS env var value via ValueFrom for container batch"}, failures)
+	assert.Empty(t, got.Spec.Containers[0].Env, "containers that aren't listed are left as is")
+	assert.Equal(t, pod.Spec.Containers[3], got.Spec.Containers[3], "containers the injection failed for are left as is")
+
+	app := got.Spec.Containers[1]
+	assert.Contains(t, app.Env, corev1.EnvVar{Name: "OTEL_SERVICE_NAME", Value: "checkout"})
+	assert.Equal(t, 1, countEnv(app.Env, "OTEL_SERVICE_NAME"), "the service name set by the user is kept")
+	assert.Contains(t, app.Env, corev1.EnvVar{Name: "OTEL_RESOURCE_ATTRIBUTES", Value: "k8s.container.name=app,k8s.pod.name=test-pod"})
+
+	worker := got.Spec.Containers[2]
+	assert.Contains(t, worker.Env, corev1.EnvVar{Name: "OTEL_SERVICE_NAME", Value: "test-pod-worker"}, "each container gets its own service name")
+	assert.Contains(t, worker.Env, corev1.EnvVar{Name: "OTEL_RESOURCE_ATTRIBUTES", Value: "k8s.container.name=worker,k8s.pod.name=test-pod"})
+	assert.Contains(t, worker.Env, corev1.EnvVar{Name: "JAVA_TOOL_OPTIONS", Value: " -javaagent:/splunk/splunk-otel-javaagent-all.jar"})
+
+	assert.Len(t, got.Spec.Volumes, 1, "the agent volume is shared by the containers")
+	assert.Len(t, got.Spec.InitContainers, 1, "the agent is copied once for all the containers")
+}
+
+func countEnv(env []corev1.EnvVar, name string) int {
+	count := 0
+	for _, e := range env {
+		if e.Name == name {
+			count++
+		}
+	}
+	return count
+}
+
+func TestInjectFunctions(t *testing.T) {
+	tag := func(name string) injectFn {
+		return func(_ context.Context, _ config, pod corev1.Pod, _ int, _ corev1.Namespace) (corev1.Pod, error) {
+			pod.Annotations[name] = "injected"
+			return pod, nil
+		}
+	}
+	h := &handler{
+		logger: logr.Discard(),
+		injectMap: map[string]injectFn{
+			annotationJava:   tag("java"),
+			annotationPython: tag("python"),
+		},
+	}
+	spec := &v1alpha1.AgentSpec{
+		Instrumentation: v1alpha1.Instrumentation{
+			Java: v1alpha1.AutoInstrumentation{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"instrumentation": "java"}},
+			},
+		},
+	}
+
+	cases := []struct {
+		desc           string
+		podAnnotations map[string]string
+		podLabels      map[string]string
+		nsName         string
+		nsAnnotations  map[string]string
+		nsLabels       map[string]string
+		injected       []string
+	}{
+		{
+			desc: "nothing enabled",
+		},
+		{
+			desc:           "pod annotation",
+			podAnnotations: map[string]string{"otel.splunk.com/inject-python": "true"},
+			injected:       []string{"python"},
+		},
+		{
+			desc:          "namespace annotation",
+			nsAnnotations: map[string]string{"otel.splunk.com/inject-python": "true"},
+			injected:      []string{"python"},
+		},
+		{
+			desc:           "pod opting out of its namespace",
+			podAnnotations: map[string]string{"otel.splunk.com/inject-python": "false"},
+			nsAnnotations:  map[string]string{"otel.splunk.com/inject-python": "true"},
+		},
+		{
+			desc:     "namespace selector",
+			nsLabels: map[string]string{"instrumentation": "java"},
+			injected: []string{"java"},
+		},
+		{
+			desc:          "namespace opting out of the selector",
+			nsAnnotations: map[string]string{"otel.splunk.com/inject-java": "false"},
+			nsLabels:      map[string]string{"instrumentation": "java"},
+		},
+		{
+			desc:           "pod opting out of the selector",
+			podAnnotations: map[string]string{"otel.splunk.com/inject-java": "false"},
+			nsAnnotations:  map[string]string{"otel.splunk.com/inject-python": "true"},
+			nsLabels:       map[string]string{"instrumentation": "java"},
+			injected:       []string{"python"},
+		},
+		{
+			desc:     "system namespace matching the selector",
+			nsName:   "kube-system",
+			nsLabels: map[string]string{"instrumentation": "java"},
+		},
+		{
+			desc:     "operator namespace matching the selector",
+			nsName:   "splunk-otel-operator-system",
+			nsLabels: map[string]string{"instrumentation": "java"},
+		},
+		{
+			desc:           "annotated pod of a system namespace",
+			podAnnotations: map[string]string{"otel.splunk.com/inject-java": "true"},
+			nsName:         "kube-system",
+			injected:       []string{"java"},
+		},
+		{
+			desc:          "collector pods",
+			podLabels:     map[string]string{"app.kubernetes.io/managed-by": "splunk-otel-collector-operator"},
+			nsAnnotations: map[string]string{"otel.splunk.com/inject-python": "true"},
+			nsLabels:      map[string]string{"instrumentation": "java"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}, Labels: tc.podLabels}}
+			for k, v := range tc.podAnnotations {
+				pod.Annotations[k] = v
+			}
+			ns := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: tc.nsName, Annotations: tc.nsAnnotations, Labels: tc.nsLabels}}
+
+			var injected []string
+			for _, fn := range h.injectFunctions(pod, ns, h.namespaceSelectors(spec)) {
+				got, err := fn(context.Background(), config{}, *pod.DeepCopy(), 0, ns)
+				require.NoError(t, err)
+				for _, name := range []string{"java", "python"} {
+					if got.Annotations[name] == "injected" {
+						injected = append(injected, name)
+					}
+				}
+			}
+			assert.ElementsMatch(t, tc.injected, injected)
+		})
+	}
+}
@@ -19,38 +19,52 @@ const (
 	annotationApp  = "app"
 )
 
-func serviceName(pod corev1.Pod, resources map[string]string) string {
-	if name := pod.Annotations[annotationApp]; name != "" {
-		return name
-	}
-	if name := pod.Annotations[annotationName]; name != "" {
-		return name
-	}
-	if name := resources[string(semconv.AttributeK8SDeploymentName)]; name != "" {
-		return name
+// serviceName returns the service name of the container at the given index, named after the workload of the pod.
+// When more than one container of the pod is instrumented, the name of the container is appended to the one of the
+// workload, so that each container reports its own service.
+func serviceName(pod corev1.Pod, idx int, resources map[string]string) string {
+	container := pod.Spec.Containers[idx].Name
+	workload := workloadName(pod, resources)
+	if workload == "" {
+		return container
 	}
-	if name := resources[string(semconv.AttributeK8SStatefulSetName)]; name != "" {
-		return name
+
+	if containers, err := targetContainers(pod); err == nil && len(containers) > 1 {
+		return fmt.Sprintf("%s-%s", workload, container)
 	}
-	if name := resources[string(semconv.AttributeK8SJobName)]; name != "" {
+	return workload
+}
+
+// workloadName returns the name of the workload of the pod, from its annotations or else from the resource attributes
+// of its owners, or an empty string when none is known.
+func workloadName(pod corev1.Pod, resources map[string]string) string {
+	if name := pod.Annotations[annotationApp]; name != "" {
 		return name
 	}
-	if name := resources[string(semconv.AttributeK8SCronJobName)]; name != "" {
+	if name := pod.Annotations[annotationName]; name != "" {
 		return name
 	}
-	if name := resources[string(semconv.AttributeK8SPodName)]; name != "" {
-		return name
+	for _, key := range []attribute.Key{
+		semconv.AttributeK8SDeploymentName,
+		semconv.AttributeK8SStatefulSetName,
+		semconv.AttributeK8SJobName,
+		semconv.AttributeK8SCronJobName,
+		semconv.AttributeK8SPodName,
+	} {
+		if name := resources[string(key)]; name != "" {
+			return name
+		}
 	}
-	return pod.Spec.Containers[0].Name
+	return ""
 }
 
-// createResourceMap creates resource attribute map.
+// createResourceMap creates resource attribute map of the container at the given index.
 // User defined attributes (in explicitly set env var) have higher precedence.
-func (h *handler) createResourceMap(ctx context.Context, ns corev1.Namespace, pod corev1.Pod) (map[string]string, int) {
+func (h *handler) createResourceMap(ctx context.Context, ns corev1.Namespace, pod corev1.Pod, idx int) (map[string]string, int) {
 
 	k8sResources := map[attribute.Key]string{}
 	k8sResources[semconv.AttributeK8SNamespaceName] = ns.Name
-	k8sResources[semconv.AttributeK8SContainerName] = pod.Spec.Containers[0].Name
+	k8sResources[semconv.AttributeK8SContainerName] = pod.Spec.Containers[idx].Name
 	// Some fields might be empty - node name, pod name
 	// The pod name might be empty if the pod is created form deployment template
 	k8sResources[semconv.AttributeK8SPodName] = pod.Name
@@ -66,9 +80,9 @@ func (h *handler) createResourceMap(ctx context.Context, ns corev1.Namespace, po
 	}
 
 	// get existing resources env var and add them to the map
-	existingResourceEnvIdx := getIndexOfEnv(pod.Spec.Containers[0].Env, envOTELResourceAttrs)
+	existingResourceEnvIdx := getIndexOfEnv(pod.Spec.Containers[idx].Env, envOTELResourceAttrs)
 	if existingResourceEnvIdx > -1 {
-		existingResArr := strings.Split(pod.Spec.Containers[0].Env[existingResourceEnvIdx].Value, ",")
+		existingResArr := strings.Split(pod.Spec.Containers[idx].Env[existingResourceEnvIdx].Value, ",")
 		for _, kv := range existingResArr {
 			keyValueArr := strings.Split(strings.TrimSpace(kv), "=")
 			if len(keyValueArr) != 2 {
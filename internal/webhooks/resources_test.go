@@ -92,9 +92,40 @@ func TestCreateResourceMap(t *testing.T) {
 				},
 			}
 			ns := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: c.namespace}}
-			attrs, idx := h.createResourceMap(context.Background(), ns, pod)
+			attrs, idx := h.createResourceMap(context.Background(), ns, pod, 0)
 			assert.Equal(t, attrs, c.attrs)
 			assert.Equal(t, idx, c.idx)
 		})
 	}
 }
+
+func TestServiceName(t *testing.T) {
+	for _, tt := range []struct {
+		desc       string
+		containers string
+		resources  map[string]string
+		idx        int
+		expected   string
+	}{
+		{"single container", "", map[string]string{"k8s.deployment.name": "shop"}, 0, "shop"},
+		{"one listed container", "worker", map[string]string{"k8s.deployment.name": "shop"}, 1, "shop"},
+		{"listed containers", "api,worker", map[string]string{"k8s.deployment.name": "shop"}, 1, "shop-worker"},
+		{"all containers", "*", map[string]string{"k8s.deployment.name": "shop"}, 0, "shop-api"},
+		{"no workload", "*", map[string]string{}, 1, "worker"},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			// prepare
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{annotationContainerNames: tt.containers},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "api"}, {Name: "worker"}},
+				},
+			}
+
+			// test and verify
+			assert.Equal(t, tt.expected, serviceName(pod, tt.idx, tt.resources))
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -23,17 +24,21 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	otelv1alpha1 "github.com/signalfx/splunk-otel-collector-operator/apis/otel/v1alpha1"
 	otelcontrollers "github.com/signalfx/splunk-otel-collector-operator/controllers/otel"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/autodetect"
+	"github.com/signalfx/splunk-otel-collector-operator/internal/collector/upgrade"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/version"
 	"github.com/signalfx/splunk-otel-collector-operator/internal/webhooks"
 	//+kubebuilder:scaffold:imports
@@ -82,6 +87,9 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "80f6591f.splunk.com",
+		// the Secrets and ConfigMaps are read from the API server: caching them would keep every Secret and
+		// ConfigMap of the cluster in memory, the controller only watches their metadata
+		ClientDisableCacheFor: []client.Object{&corev1.Secret{}, &corev1.ConfigMap{}},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -115,6 +123,15 @@ func main() {
 	})
 	//+kubebuilder:scaffold:builder
 
+	// upgrades the managed instances once the manager is ready, only the elected leader runs it
+	err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		return upgrade.ManagedInstances(ctx, ctrl.Log.WithName("collector-upgrade"), version.Get(), mgr.GetClient(), mgr.GetEventRecorderFor("splunk-otel-operator"))
+	}))
+	if err != nil {
+		setupLog.Error(err, "unable to add the upgrade routine to the manager")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)